@@ -1,6 +1,68 @@
 package loafer
 
+import "time"
+
 const (
+	// SLACKAPIURL - Default Slack Web API base url
+	SLACKAPIURL = "https://slack.com/api/"
+
+	// SLACKAUTHORIZEURL - Slack OAuth v2 authorize url
+	SLACKAUTHORIZEURL = "https://slack.com/oauth/v2/authorize"
+
+	// OAUTHSTATECOOKIE - Cookie holding the OAuth state of an install
+	OAUTHSTATECOOKIE = "loafer_oauth_state"
+
+	// OAUTHSTATELIFETIME - How long an install can take between its start and its callback
+	OAUTHSTATELIFETIME = 10 * time.Minute
+
+	// DEFAULTMAXCLOCKSKEW - Default max age of a slack request timestamp
+	DEFAULTMAXCLOCKSKEW = 5 * time.Minute
+
+	// DEFAULTHANDLERTIMEOUT - Default deadline of a handler context, slack stops waiting after 3 seconds
+	DEFAULTHANDLERTIMEOUT = 3 * time.Second
+
+	// DEFAULTWORKERS - Default max handlers running in the background
+	DEFAULTWORKERS = 10
+
+	// DEFAULTQUEUESIZE - Default max handlers waiting for a worker
+	DEFAULTQUEUESIZE = 100
+
+	// RESPONSEURLMAXUSES - Times a response_url can be used
+	RESPONSEURLMAXUSES = 5
+
+	// RESPONSEURLLIFETIME - How long a response_url can be used for
+	RESPONSEURLLIFETIME = 30 * time.Minute
+
+	// DEFAULTEVENTTTL - Default time event ids are remembered to drop retries, slack retries for a few minutes
+	DEFAULTEVENTTTL = time.Hour
+
+	// DEFAULTMAXRETRIES - Default retries of a rate limited Web API call
+	DEFAULTMAXRETRIES = 3
+
+	// TIER1 - Web API methods allowing 1+ calls per minute
+	TIER1 RateTier = 1
+	// TIER2 - Web API methods allowing 20+ calls per minute
+	TIER2 RateTier = 2
+	// TIER3 - Web API methods allowing 50+ calls per minute
+	TIER3 RateTier = 3
+	// TIER4 - Web API methods allowing 100+ calls per minute
+	TIER4 RateTier = 4
+	// TIERPOSTMESSAGE - chat.postMessage, 1 call per second per channel
+	TIERPOSTMESSAGE RateTier = 5
+
+	// ARGSTRING - Subcommand argument kept as text
+	ARGSTRING CommandArgType = 0
+	// ARGINT - Subcommand argument parsed as an int
+	ARGINT CommandArgType = 1
+	// ARGDURATION - Subcommand argument parsed as a duration, e.g. 30m or 2d
+	ARGDURATION CommandArgType = 2
+	// ARGUSER - Subcommand argument parsed from a user mention
+	ARGUSER CommandArgType = 3
+	// ARGCHANNEL - Subcommand argument parsed from a channel mention
+	ARGCHANNEL CommandArgType = 4
+	// ARGENUM - Subcommand argument that must be one of its Values
+	ARGENUM CommandArgType = 5
+
 	// INSTALLSUCCESSPAGE - Default Installation Page
 	INSTALLSUCCESSPAGE = `
 		<!DOCTYPE html>
@@ -57,4 +119,40 @@ const (
 		</body>
 		</html>
 	`
+
+	// INSTALLCANCELEDPAGE - Default page of an installation canceled by the user
+	INSTALLCANCELEDPAGE = `
+		<!DOCTYPE html>
+		<html lang="en">
+		<head>
+			<meta charset="UTF-8">
+			<meta name="viewport" content="width=device-width, initial-scale=1.0">
+			<title>Document</title>
+			<style>
+				html, body, .install-canceled {
+					height: 100%;
+					width: 100%;
+					
+				}
+				.install-canceled {
+					display: flex;
+					justify-content: center;
+					align-items: center;
+				}
+				.card {
+					box-shadow: 0 3px 6px rgba(0,0,0,0.16), 0 3px 6px rgba(0,0,0,0.23);
+					padding: 20px 50px;
+				}
+			</style>
+		</head>
+		<body>
+			<div class="install-canceled">
+				<div class="card">
+					<h1>{{APP_NAME}}</h1>
+					<p style="padding: 20px 0;">The installation of {{APP_NAME}} was canceled, nothing was added to your workspace.</p>
+				</div>
+			</div>
+		</body>
+		</html>
+	`
 )
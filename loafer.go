@@ -6,17 +6,37 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// ErrAcknowledged - The request was already acknowledged by Ack, reply through the response_url or the Web API instead
+var ErrAcknowledged = errors.New("Request was already acknowledged by Ack")
+
+// ErrQueueFull - The worker pool has QueueSize handlers waiting already, the request is answered with a 503
+var ErrQueueFull = errors.New("Too many handlers waiting for a worker")
+
+// Use - Add middleware that runs around every command, action, shortcut, view, options and event handler
+func (a *SlackApp) Use(middleware ...Middleware) {
+	a.middleware = append(a.middleware, middleware...)
+}
+
 // OnCommand - Add handler to command
-func (a *SlackApp) OnCommand(cmd string, handler func(ctx *SlackContext)) {
-	a.cmds[cmd] = handler
+func (a *SlackApp) OnCommand(cmd string, handler func(ctx *SlackContext), middleware ...Middleware) {
+	a.cmds[cmd] = chain(handlerFunc(handler), middleware)
+}
+
+// HandleCommand - Add handler that can return an error to command
+func (a *SlackApp) HandleCommand(cmd string, handler Handler, middleware ...Middleware) {
+	a.cmds[cmd] = chain(handler, middleware)
 }
 
 // RemoveCommand - Remove a command to the app base on command
@@ -25,28 +45,68 @@ func (a *SlackApp) RemoveCommand(cmd string) {
 }
 
 // OnAction - Add an action handler to the app base on action_id
-func (a *SlackApp) OnAction(actionID string, handler func(ctx *SlackContext)) {
-	a.actionListeners[actionID] = handler
+func (a *SlackApp) OnAction(actionID string, handler func(ctx *SlackContext), middleware ...Middleware) {
+	a.actionListeners[actionID] = chain(handlerFunc(handler), middleware)
+}
+
+// HandleAction - Add an action handler that can return an error base on action_id
+func (a *SlackApp) HandleAction(actionID string, handler Handler, middleware ...Middleware) {
+	a.actionListeners[actionID] = chain(handler, middleware)
 }
 
 // OnShortcut - Add an shortcut handler to the app base on callback_id
-func (a *SlackApp) OnShortcut(callbackID string, handler func(ctx *SlackContext)) {
-	a.shortcutListeners[callbackID] = handler
+func (a *SlackApp) OnShortcut(callbackID string, handler func(ctx *SlackContext), middleware ...Middleware) {
+	a.shortcutListeners[callbackID] = chain(handlerFunc(handler), middleware)
+}
+
+// HandleShortcut - Add an shortcut handler that can return an error base on callback_id
+func (a *SlackApp) HandleShortcut(callbackID string, handler Handler, middleware ...Middleware) {
+	a.shortcutListeners[callbackID] = chain(handler, middleware)
 }
 
 // OnViewSubmission - Add handler to view submission base on callback_id
-func (a *SlackApp) OnViewSubmission(callbackID string, handler func(ctx *SlackContext)) {
-	a.submitListeners[callbackID] = handler
+func (a *SlackApp) OnViewSubmission(callbackID string, handler func(ctx *SlackContext), middleware ...Middleware) {
+	a.submitListeners[callbackID] = chain(handlerFunc(handler), middleware)
+}
+
+// HandleViewSubmission - Add handler that can return an error to view submission base on callback_id
+func (a *SlackApp) HandleViewSubmission(callbackID string, handler Handler, middleware ...Middleware) {
+	a.submitListeners[callbackID] = chain(handler, middleware)
 }
 
 // OnViewClose - Add handler to view close base on callback_id
-func (a *SlackApp) OnViewClose(callbackID string, handler func(ctx *SlackContext)) {
-	a.closeListeners[callbackID] = handler
+func (a *SlackApp) OnViewClose(callbackID string, handler func(ctx *SlackContext), middleware ...Middleware) {
+	a.closeListeners[callbackID] = chain(handlerFunc(handler), middleware)
+}
+
+// HandleViewClose - Add handler that can return an error to view close base on callback_id
+func (a *SlackApp) HandleViewClose(callbackID string, handler Handler, middleware ...Middleware) {
+	a.closeListeners[callbackID] = chain(handler, middleware)
+}
+
+// OnOptions - Add an options handler to external selects base on action_id
+func (a *SlackApp) OnOptions(actionID string, handler func(ctx *SlackContext, query string) (*SlackOptionsResponse, error), middleware ...Middleware) {
+	a.optionsListeners[actionID] = chain(optionsHandler(handler), middleware)
 }
 
 // OnEvent - Add handler to events
-func (a *SlackApp) OnEvent(eventType string, handler func(ctx *SlackContext)) {
-	a.eventListeners[eventType] = handler
+func (a *SlackApp) OnEvent(eventType string, handler func(ctx *SlackContext), middleware ...Middleware) {
+	a.eventListeners[eventType] = chain(handlerFunc(handler), middleware)
+}
+
+// HandleEvent - Add handler that can return an error to events
+func (a *SlackApp) HandleEvent(eventType string, handler Handler, middleware ...Middleware) {
+	a.eventListeners[eventType] = chain(handler, middleware)
+}
+
+// OnAppHomeOpened - Add handler to app_home_opened events of a tab, home or messages, an empty tab matches both
+func (a *SlackApp) OnAppHomeOpened(tab string, handler func(ctx *SlackContext), middleware ...Middleware) {
+	a.homeListeners[tab] = chain(handlerFunc(handler), middleware)
+}
+
+// HandleAppHomeOpened - Add handler that can return an error to app_home_opened events of a tab
+func (a *SlackApp) HandleAppHomeOpened(tab string, handler Handler, middleware ...Middleware) {
+	a.homeListeners[tab] = chain(handler, middleware)
 }
 
 // OnError - Add handler to errors
@@ -54,6 +114,21 @@ func (a *SlackApp) OnError(handler func(res http.ResponseWriter, req *http.Reque
 	a.errorCB = handler
 }
 
+// OnURLVerification - Add handler to the url_verification requests slack sends when the events url is set
+func (a *SlackApp) OnURLVerification(cb func(req *http.Request, challenge string)) {
+	a.verificationCB = cb
+}
+
+// OnAppUninstall - Add handler that runs once a workspace uninstalled the app or revoked its bot token, after its token was removed from the TokensCache
+func (a *SlackApp) OnAppUninstall(handler func(ctx *SlackContext) error) {
+	a.uninstallCB = handler
+}
+
+// OnUserTokensRevoked - Add handler to the user tokens revoked by tokens_revoked events, loafer only keeps bot tokens so removing user tokens is up to handler
+func (a *SlackApp) OnUserTokensRevoked(handler func(ctx *SlackContext, userIDs []string) error) {
+	a.userRevokeCB = handler
+}
+
 // OnAppInstall - Add handler to app distribution after it's been successfully installed
 func (a *SlackApp) OnAppInstall(cb func(installRes *SlackOauth2Response, res http.ResponseWriter, req *http.Request) bool) {
 	a.distCB = cb
@@ -61,33 +136,40 @@ func (a *SlackApp) OnAppInstall(cb func(installRes *SlackOauth2Response, res htt
 
 // appInstall - Handler for app distribution
 func (a *SlackApp) appInstall(res http.ResponseWriter, req *http.Request) {
+	if req.URL.Query().Get("error") == "access_denied" {
+		a.clearOAuthState(res)
+		Response(&SlackContext{Res: res}, http.StatusOK, []byte(strings.Replace(INSTALLCANCELEDPAGE, "{{APP_NAME}}", a.opts.Name, -1)), map[string]string{
+			"Content-Type": "text/html; charset=utf-8"})
+		return
+	}
+	if err := a.verifyOAuthState(req); err != nil {
+		a.errorHandling(res, req, &SlackRequestError{Status: http.StatusForbidden, Err: err})
+		return
+	}
+	a.clearOAuthState(res)
+	if slackErr := req.URL.Query().Get("error"); len(slackErr) > 0 {
+		a.errorHandling(res, req, &SlackRequestError{Status: http.StatusBadRequest, Err: fmt.Errorf("Slack App installation failed: %s", slackErr)})
+		return
+	}
 	var installResponse SlackOauth2Response
 	form := url.Values{
 		"code":          []string{req.URL.Query().Get("code")},
 		"client_id":     []string{a.opts.ClientID},
 		"client_secret": []string{a.opts.ClientSecret}}
-	resp, err := http.Post("https://slack.com/api/oauth.v2.access", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
-	if err != nil {
-		a.errorHandling(res, req, err)
-		return
+	if len(a.opts.RedirectURL) > 0 {
+		form.Set("redirect_uri", a.opts.RedirectURL)
 	}
-	defer resp.Body.Close()
-	err = json.NewDecoder(resp.Body).Decode(&installResponse)
-	if err != nil {
+	if err := a.Client("").call(req.Context(), "oauth.v2.access", form, &installResponse); err != nil {
 		a.errorHandling(res, req, err)
 		return
 	}
-	if installResponse.Ok {
-		avoidDefaultPage := false
-		if a.distCB != nil {
-			avoidDefaultPage = a.distCB(&installResponse, res, req)
-		}
-		if !avoidDefaultPage {
-			Response(&SlackContext{Res: res}, http.StatusOK, []byte(strings.Replace(INSTALLSUCCESSPAGE, "{{APP_NAME}}", a.opts.Name, -1)), map[string]string{
-				"Content-Type": "text/html; charset=utf-8"})
-		}
-	} else {
-		Response(&SlackContext{Res: res}, http.StatusInternalServerError, []byte("Slack App Access Request is not Ok"), nil)
+	avoidDefaultPage := false
+	if a.distCB != nil {
+		avoidDefaultPage = a.distCB(&installResponse, res, req)
+	}
+	if !avoidDefaultPage {
+		Response(&SlackContext{Res: res}, http.StatusOK, []byte(strings.Replace(INSTALLSUCCESSPAGE, "{{APP_NAME}}", a.opts.Name, -1)), map[string]string{
+			"Content-Type": "text/html; charset=utf-8"})
 	}
 }
 
@@ -98,76 +180,236 @@ func (a *SlackApp) checkSlackSecret(signing string, ts string, body string) bool
 	tested := hmac.New(sha256.New, []byte(signed))
 	tested.Write([]byte(data))
 	own := strings.Join([]string{"v0", hex.EncodeToString(tested.Sum(nil))}, "=")
-	if own == signing {
-		return true
+	return hmac.Equal([]byte(own), []byte(signing))
+}
+
+// verifySlackRequest - Checking the signature, timestamp and replay of a slack request
+func (a *SlackApp) verifySlackRequest(req *http.Request, body []byte) bool {
+	signing := req.Header.Get("X-Slack-Signature")
+	ts := req.Header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	maxSkew := a.opts.MaxClockSkew
+	if maxSkew <= 0 {
+		maxSkew = DEFAULTMAXCLOCKSKEW
 	}
-	return false
+	sentAt := time.Unix(seconds, 0)
+	if age := time.Since(sentAt); age > maxSkew || age < -maxSkew {
+		return false
+	}
+	if !a.checkSlackSecret(signing, ts, string(body)) {
+		return false
+	}
+	if a.signatures != nil && !a.signatures.add(signing, sentAt.Add(maxSkew)) {
+		return false
+	}
+	return true
+}
+
+// add - Record a signature until it expires, returns false if it has been seen already
+func (c *signatureCache) add(signing string, expiry time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seen.add(signing, expiry, time.Now())
+}
+
+// add - Record a key until expiry, returns false if it is recorded already and hasn't expired
+func (s *expiringSet) add(key string, expiry time.Time, now time.Time) bool {
+	s.purge(now)
+	if exp, ok := s.expiries[key]; ok && now.Before(exp) {
+		return false
+	}
+	if s.expiries == nil {
+		s.expiries = make(map[string]time.Time)
+	}
+	s.expiries[key] = expiry
+	s.order = append(s.order, expiringKey{key: key, expiry: expiry})
+	return true
+}
+
+// claim - Mark a key in flight, false when it is in flight already
+func (f *inFlight) claim(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.keys[key] {
+		return false
+	}
+	f.keys[key] = true
+	return true
+}
+
+// release - Mark a claimed key done
+func (f *inFlight) release(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.keys, key)
+}
+
+// remove - Remove a key, its entry in the insertion order is skipped once purged
+func (s *expiringSet) remove(key string) {
+	delete(s.expiries, key)
+}
+
+// purge - Remove the expired keys at the front of the insertion order, keys expiring out of order wait for the ones before them
+func (s *expiringSet) purge(now time.Time) {
+	for len(s.order) > 0 && now.After(s.order[0].expiry) {
+		front := s.order[0]
+		if exp, ok := s.expiries[front.key]; ok && exp.Equal(front.expiry) {
+			delete(s.expiries, front.key)
+			if s.expired != nil {
+				s.expired(front.key)
+			}
+		}
+		s.order = s.order[1:]
+	}
+}
+
+// InitializeEventStore - Return an in-memory EventStore remembering event ids for ttl
+func InitializeEventStore(ttl time.Duration) EventStore {
+	return &memoryEventStore{ttl: ttl}
+}
+
+// Seen - Records the event id, true when it was already recorded within the ttl
+func (s *memoryEventStore) Seen(eventID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	return !s.seen.add(eventID, now.Add(s.ttl), now)
+}
+
+// Forget - Removes the event id so its next delivery is handled
+func (s *memoryEventStore) Forget(eventID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen.remove(eventID)
 }
 
 // interaction - Slack App interactions handler
 func (a *SlackApp) interactions(res http.ResponseWriter, req *http.Request) {
-	var event SlackInteractionEvent
 	bodyText, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		a.errorHandling(res, req, err)
 		return
 	}
 	defer req.Body.Close()
+	isAuthorizedCaller := a.verifySlackRequest(req, bodyText)
+	if isAuthorizedCaller {
+		a.handleInteraction(res, req, bodyText)
+	} else {
+		Response(&SlackContext{Res: res}, http.StatusUnauthorized, []byte("Unauthorized"), nil)
+	}
+}
+
+// handleInteraction - Dispatch a verified interaction payload to its handler
+func (a *SlackApp) handleInteraction(res http.ResponseWriter, req *http.Request, bodyText []byte) {
+	var event SlackInteractionEvent
 	queries, err := url.ParseQuery(string(bodyText))
 	if err != nil {
 		a.errorHandling(res, req, err)
 		return
 	}
-	isAuthorizedCaller := a.checkSlackSecret(req.Header.Get("X-Slack-Signature"), req.Header.Get("X-Slack-Request-TimeStamp"), string(bodyText))
-	if isAuthorizedCaller {
-		err = json.Unmarshal([]byte(queries.Get("payload")), &event)
-		if err != nil {
-			a.errorHandling(res, req, err)
-			return
+	err = json.Unmarshal([]byte(queries.Get("payload")), &event)
+	if err != nil {
+		a.errorHandling(res, req, err)
+		return
+	}
+	key := interactionInstallation(&event)
+	accessToken, installation, err := a.installationToken(key)
+	if err != nil {
+		a.errorHandling(res, req, err)
+		return
+	}
+	ctx, cancel := a.newSlackContext(res, req, bodyText, accessToken, key.workspace())
+	ctx.Installation = installation
+	defer cancel()
+	ctx.Interaction = &event
+	ctx.ResponseURL = event.ResponseURL
+	if len(event.ResponseURLs) > 0 {
+		ctx.ResponseURL = event.ResponseURLs[0].ResponseURL
+	}
+	if len(ctx.ResponseURL) > 0 {
+		a.responseURLs.track(ctx.ResponseURL)
+	}
+	switch Type := event.Type; Type {
+	case "shortcut":
+		callbackID := event.CallbackID
+		if handler, ok := a.shortcutListeners[callbackID]; ok {
+			a.run(ctx, handler)
+		} else {
+			a.errorHandling(ctx.Res, req, unrecognized("Unrecognized shortcut: %s", callbackID))
 		}
-		accessToken := a.opts.TokensCache.Get(event.Team.ID)
-		if len(accessToken) == 0 {
-			a.errorHandling(res, req, fmt.Errorf("App is not installed to workspace: %s", event.Team.ID))
-			return
+	case "block_actions":
+		action := ctx.Action()
+		if action == nil {
+			a.errorHandling(ctx.Res, req, unrecognized("Block actions without an action"))
+		} else if handler, ok := a.actionListeners[action.ActionID]; ok {
+			a.run(ctx, handler)
+		} else {
+			a.errorHandling(ctx.Res, req, unrecognized("Unrecognized action: %s", action.ActionID))
 		}
-		ctx := &SlackContext{
-			Body:      bodyText,
-			Token:     accessToken,
-			Workspace: event.Team.ID,
-			Res:       res,
-			Req:       req}
-		switch Type := event.Type; Type {
-		case "shortcut":
-			callbackID := event.CallbackID
-			if handler, ok := a.shortcutListeners[callbackID]; ok {
-				handler(ctx)
-			} else {
-				a.errorHandling(res, req, fmt.Errorf("Unrecognized shortcut: %s", callbackID))
-			}
-		case "block_actions":
-			action := event.Actions[0]
-			if handler, ok := a.actionListeners[action.ActionID]; ok {
-				handler(ctx)
-			} else {
-				a.errorHandling(res, req, fmt.Errorf("Unrecognized action: %s", action.ActionID))
-			}
-		case "view_submission":
-			if handler, ok := a.submitListeners[event.View.CallbackID]; ok {
-				handler(ctx)
-			} else {
-				a.errorHandling(res, req, fmt.Errorf("Unrecognized submission event from view: %s", event.View.CallbackID))
-			}
-		case "view_closed":
-			if handler, ok := a.closeListeners[event.View.CallbackID]; ok {
-				handler(ctx)
-			} else {
-				a.errorHandling(res, req, fmt.Errorf("Unrecognized closed event from view: %s", event.View.CallbackID))
+	case "view_submission":
+		if handler, ok := a.submitListeners[event.View.CallbackID]; ok {
+			a.run(ctx, handler)
+		} else {
+			a.errorHandling(ctx.Res, req, unrecognized("Unrecognized submission event from view: %s", event.View.CallbackID))
+		}
+	case "view_closed":
+		if handler, ok := a.closeListeners[event.View.CallbackID]; ok {
+			a.run(ctx, handler)
+		} else {
+			a.errorHandling(ctx.Res, req, unrecognized("Unrecognized closed event from view: %s", event.View.CallbackID))
+		}
+	case "block_suggestion":
+		if handler, ok := a.optionsListeners[event.ActionID]; ok {
+			a.run(ctx, handler)
+		} else {
+			a.errorHandling(ctx.Res, req, unrecognized("Unrecognized options request: %s", event.ActionID))
+		}
+	default:
+		a.errorHandling(ctx.Res, req, unrecognized("Unrecognized interaction type: %s", event.Type))
+	}
+}
+
+// newSlackContext - Build the context passed to handlers, its Context is done once slack stops waiting for a response
+func (a *SlackApp) newSlackContext(res http.ResponseWriter, req *http.Request, body []byte, token string, workspace string) (*SlackContext, context.CancelFunc) {
+	timeout := a.opts.HandlerTimeout
+	if timeout <= 0 {
+		timeout = DEFAULTHANDLERTIMEOUT
+	}
+	reqCtx, cancel := context.WithTimeout(req.Context(), timeout)
+	return &SlackContext{
+		Body:      body,
+		Token:     token,
+		Workspace: workspace,
+		Context:   reqCtx,
+		Res:       &responseTracker{ResponseWriter: res},
+		Req:       req,
+		app:       a}, cancel
+}
+
+// optionsHandler - Handler that sends the options of an options handler back to slack
+func optionsHandler(handler func(ctx *SlackContext, query string) (*SlackOptionsResponse, error)) Handler {
+	return func(ctx *SlackContext) error {
+		if ctx.acknowledged() {
+			return ErrAcknowledged
+		}
+		options, err := handler(ctx, ctx.Interaction.Value)
+		if err != nil {
+			return err
+		}
+		// Both fields are omitempty, slack needs an empty options list to show no results
+		jsonOptions := []byte(`{"options":[]}`)
+		if options != nil && (len(options.Options) > 0 || len(options.OptionGroups) > 0) {
+			jsonOptions, err = json.Marshal(options)
+			if err != nil {
+				return err
 			}
-		default:
-			a.errorHandling(res, req, fmt.Errorf("Unrecognized interaction type: %s", event.Type))
 		}
-	} else {
-		Response(&SlackContext{Res: res}, http.StatusUnauthorized, []byte("Unauthorized"), nil)
+		Response(ctx, http.StatusOK, jsonOptions, map[string]string{
+			"Content-Type": "application/json"})
+		return nil
 	}
 }
 
@@ -179,37 +421,120 @@ func (a *SlackApp) events(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 	defer req.Body.Close()
+	isAuthorizedCaller := a.verifySlackRequest(req, body)
+	if isAuthorizedCaller {
+		a.handleEvent(res, req, body)
+	} else {
+		Response(&SlackContext{Res: res}, http.StatusUnauthorized, []byte("Unauthorized"), nil)
+	}
+}
+
+// handleEvent - Dispatch a verified event callback to its handler
+func (a *SlackApp) handleEvent(res http.ResponseWriter, req *http.Request, body []byte) {
 	var event SlackSubscriptionEventRequest
-	err = json.Unmarshal(body, &event)
+	err := json.Unmarshal(body, &event)
 	if err != nil {
 		a.errorHandling(res, req, err)
 		return
 	}
 	if event.Type == "url_verification" {
-		Response(&SlackContext{Res: res}, http.StatusOK, body, map[string]string{
-			"Content-Type": "application/json"})
+		if a.verificationCB != nil {
+			a.verificationCB(req, event.Challenge)
+		}
+		Response(&SlackContext{Res: res}, http.StatusOK, []byte(event.Challenge), map[string]string{
+			"Content-Type": "text/plain"})
 		return
 	}
-	isAuthorizedCaller := a.checkSlackSecret(req.Header.Get("X-Slack-Signature"), req.Header.Get("X-Slack-Request-TimeStamp"), string(body))
-	if isAuthorizedCaller {
-		accessToken := a.opts.TokensCache.Get(event.TeamID)
-		if len(accessToken) == 0 {
-			a.errorHandling(res, req, fmt.Errorf("App is not installed for workspace: %s", event.TeamID))
-			return
-		}
-		ctx := &SlackContext{
-			Body:      body,
-			Token:     accessToken,
-			Workspace: event.TeamID,
-			Res:       res,
-			Req:       req}
-		if handler, ok := a.eventListeners[event.Event.Type]; ok {
-			handler(ctx)
-		} else {
-			a.errorHandling(res, req, fmt.Errorf("Unrecognized event: %s", event.Event.Type))
+	// Slack sends both app_uninstalled and tokens_revoked on uninstall, the second finds no token and still runs its handlers
+	uninstall := event.Event.Type == "app_uninstalled" || event.Event.Type == "tokens_revoked"
+	accessToken, installation, err := a.installationToken(eventInstallation(&event))
+	installed := err == nil
+	if err != nil && !uninstall {
+		a.errorHandling(res, req, err)
+		return
+	}
+	if a.opts.NoRetry {
+		res.Header().Set("X-Slack-No-Retry", "1")
+	}
+	if len(event.EventID) > 0 && a.eventIDs.Seen(event.EventID) {
+		Response(&SlackContext{Res: res}, http.StatusOK, nil, nil)
+		return
+	}
+	workspace := event.TeamID
+	if len(workspace) == 0 {
+		workspace = installation.workspace()
+	}
+	ctx, cancel := a.newSlackContext(res, req, body, accessToken, workspace)
+	ctx.Installation = installation
+	defer cancel()
+	ctx.Event = &event
+	ctx.RetryNum, _ = strconv.Atoi(req.Header.Get("X-Slack-Retry-Num"))
+	ctx.RetryReason = req.Header.Get("X-Slack-Retry-Reason")
+	handler, ok := a.eventHandler(&event.Event)
+	if uninstall {
+		handler, ok = a.uninstallHandler(handler, installed), true
+	}
+	if ok {
+		if !a.run(ctx, handler) && len(event.EventID) > 0 {
+			a.eventIDs.Forget(event.EventID)
 		}
 	} else {
-		Response(&SlackContext{Res: res}, http.StatusUnauthorized, []byte("Unauthorized"), nil)
+		a.errorHandling(ctx.Res, req, unrecognized("Unrecognized event: %s", event.Event.Type))
+	}
+}
+
+// eventHandler - Handler of an event, by tab for app_home_opened and by subtype for messages
+func (a *SlackApp) eventHandler(event *SlackSubscriptionEvent) (Handler, bool) {
+	switch event.Type {
+	case "app_home_opened":
+		if handler, ok := a.homeListeners[event.Tab]; ok {
+			return handler, true
+		}
+		if handler, ok := a.homeListeners[""]; ok {
+			return handler, true
+		}
+	case "message":
+		if handler, ok := a.eventListeners["message."+event.Subtype]; ok && len(event.Subtype) > 0 {
+			return handler, true
+		}
+	}
+	handler, ok := a.eventListeners[event.Type]
+	return handler, ok
+}
+
+// uninstallHandler - Handler removing the token of a workspace that uninstalled the app or revoked its bot token, then running next if any.
+// The token and the uninstall callback are only handled while installed and by one delivery at a time, so they run once per installation
+func (a *SlackApp) uninstallHandler(next Handler, installed bool) Handler {
+	return func(ctx *SlackContext) error {
+		revoked := ctx.Event.Event.Type == "app_uninstalled"
+		if !revoked {
+			var event SlackTokensRevokedEvent
+			if err := ctx.DecodeEvent(&event); err != nil {
+				return err
+			}
+			revoked = len(event.Tokens.Bot) > 0
+			if len(event.Tokens.OAuth) > 0 && a.userRevokeCB != nil {
+				if err := a.userRevokeCB(ctx, event.Tokens.OAuth); err != nil {
+					return err
+				}
+			}
+		}
+		// The token is removed once the callback succeeded, a failed callback runs again on slack's retry
+		if installed && revoked && a.uninstalls.claim(ctx.Installation.String()) {
+			defer a.uninstalls.release(ctx.Installation.String())
+			if len(a.getToken(ctx.Installation)) > 0 {
+				if a.uninstallCB != nil {
+					if err := a.uninstallCB(ctx); err != nil {
+						return err
+					}
+				}
+				a.removeToken(ctx.Installation)
+			}
+		}
+		if next != nil {
+			return next(ctx)
+		}
+		return nil
 	}
 }
 
@@ -221,37 +546,68 @@ func (a *SlackApp) commands(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 	defer req.Body.Close()
+	isAuthorizedCaller := a.verifySlackRequest(req, bodyText)
+	if isAuthorizedCaller {
+		a.handleCommand(res, req, bodyText)
+	} else {
+		Response(&SlackContext{Res: res}, http.StatusUnauthorized, []byte("Unauthorized"), nil)
+	}
+}
+
+// handleCommand - Dispatch a verified slash command to its handler
+func (a *SlackApp) handleCommand(res http.ResponseWriter, req *http.Request, bodyText []byte) {
 	queries, err := url.ParseQuery(string(bodyText))
 	if err != nil {
 		a.errorHandling(res, req, err)
 		return
 	}
-	isAuthorizedCaller := a.checkSlackSecret(req.Header.Get("X-Slack-Signature"), req.Header.Get("X-Slack-Request-TimeStamp"), string(bodyText))
-	if isAuthorizedCaller {
-		accessToken := a.opts.TokensCache.Get(queries.Get("team_id"))
-		if len(accessToken) == 0 {
-			a.errorHandling(res, req, fmt.Errorf("App not installed for workspace: %s", queries.Get("team_id")))
-			return
-		}
-		ctx := &SlackContext{
-			Body:      bodyText,
-			Token:     accessToken,
-			Workspace: queries.Get("team_id"),
-			Res:       res,
-			Req:       req}
-		if handler, ok := a.cmds[queries.Get("command")]; ok {
-			handler(ctx)
-		} else {
-			a.errorHandling(res, req, fmt.Errorf("Unrecognized command: %s", queries.Get("command")))
-		}
+	command := parseSlashCommand(queries)
+	accessToken, installation, err := a.installationToken(InstallationKey{
+		EnterpriseID:        command.EnterpriseID,
+		TeamID:              command.TeamID,
+		IsEnterpriseInstall: command.IsEnterpriseInstall})
+	if err != nil {
+		a.errorHandling(res, req, err)
+		return
+	}
+	ctx, cancel := a.newSlackContext(res, req, bodyText, accessToken, command.TeamID)
+	ctx.Installation = installation
+	defer cancel()
+	ctx.Command = command
+	ctx.ResponseURL = command.ResponseURL
+	if len(ctx.ResponseURL) > 0 {
+		a.responseURLs.track(ctx.ResponseURL)
+	}
+	if handler, ok := a.cmds[command.Command]; ok {
+		a.run(ctx, handler)
 	} else {
-		Response(&SlackContext{Res: res}, http.StatusUnauthorized, []byte("Unauthorized"), nil)
+		a.errorHandling(ctx.Res, req, unrecognized("Unrecognized command: %s", command.Command))
 	}
 }
 
+// parseSlashCommand - Decode the form of a slash command
+func parseSlashCommand(form url.Values) *SlashCommand {
+	return &SlashCommand{
+		Token:               form.Get("token"),
+		Command:             form.Get("command"),
+		Text:                form.Get("text"),
+		TeamID:              form.Get("team_id"),
+		TeamDomain:          form.Get("team_domain"),
+		EnterpriseID:        form.Get("enterprise_id"),
+		EnterpriseName:      form.Get("enterprise_name"),
+		IsEnterpriseInstall: form.Get("is_enterprise_install") == "true",
+		ChannelID:           form.Get("channel_id"),
+		ChannelName:         form.Get("channel_name"),
+		UserID:              form.Get("user_id"),
+		UserName:            form.Get("user_name"),
+		APIAppID:            form.Get("api_app_id"),
+		TriggerID:           form.Get("trigger_id"),
+		ResponseURL:         form.Get("response_url")}
+}
+
 // CustomRoute - Add custom route
 func (a *SlackApp) CustomRoute(pattern string, handler func(res http.ResponseWriter, req *http.Request)) {
-	http.HandleFunc("/"+a.opts.Prefix+"/"+strings.Trim(pattern, "/"), handler)
+	a.mux.HandleFunc("/"+a.opts.Prefix+"/"+strings.Trim(pattern, "/"), handler)
 }
 
 // defaultRoute - handling defualt root path /
@@ -263,46 +619,105 @@ func defaultRoute(res http.ResponseWriter, req *http.Request) {
 	Response(&SlackContext{Res: res}, http.StatusOK, nil, nil)
 }
 
+// ServeHTTP - Route slack requests, the app can be mounted on any router or http.Server
+func (a *SlackApp) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	prefix := "/" + a.opts.Prefix + "/"
+	switch req.URL.Path {
+	case prefix + "events":
+		a.events(res, req)
+		return
+	case prefix + "install":
+		a.appInstall(res, req)
+		return
+	case prefix + "install/start":
+		a.installStart(res, req)
+		return
+	case prefix + "commands":
+		a.commands(res, req)
+		return
+	}
+	if _, pattern := a.mux.Handler(req); len(pattern) > 0 {
+		a.mux.ServeHTTP(res, req)
+		return
+	}
+	if strings.HasPrefix(req.URL.Path, prefix) {
+		a.interactions(res, req)
+		return
+	}
+	defaultRoute(res, req)
+}
+
 // ServeApp - Listen and Serve App on desired port, callback can be nil
 func (a *SlackApp) ServeApp(port uint16, cb func()) {
 	if len(a.opts.Prefix) == 0 {
 		panic(fmt.Sprintf("\x1b[31m%s\x1b[0m\n", "Slack App Route Prefix Cannot Be Empty"))
 	}
-	a.server = &http.Server{Addr: fmt.Sprintf(":%d", port)}
-	http.HandleFunc("/", defaultRoute)
-	http.HandleFunc(fmt.Sprintf("/%s/events", a.opts.Prefix), a.events)
-	http.HandleFunc(fmt.Sprintf("/%s/install", a.opts.Prefix), a.appInstall)
-	http.HandleFunc(fmt.Sprintf("/%s/commands", a.opts.Prefix), a.commands)
-	http.HandleFunc(fmt.Sprintf("/%s/", a.opts.Prefix), a.interactions)
+	a.server = &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: a}
 	if cb != nil {
 		go cb()
 	}
 	a.server.ListenAndServe()
 }
 
-// Close - Shutting down the server
+// Close - Shutting down the server and waiting for the handlers running in the background
 func (a *SlackApp) Close(ctx context.Context) {
 	if a.server != nil {
 		if err := a.server.Shutdown(ctx); err != nil {
 			panic(err)
 		}
 	}
+	if err := a.pool.drain(ctx); err != nil {
+		log.Println(err.Error())
+	}
 }
 
 // InitializeSlackApp - Return an instance of SlackApp
 func InitializeSlackApp(opts *SlackAppOptions) SlackApp {
+	var signatures *signatureCache
+	if opts.RejectReplays {
+		signatures = &signatureCache{}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DEFAULTWORKERS
+	}
+	queueSize := opts.QueueSize
+	if queueSize <= 0 {
+		queueSize = DEFAULTQUEUESIZE
+	}
+	events := opts.EventStore
+	if events == nil {
+		events = InitializeEventStore(DEFAULTEVENTTTL)
+	}
 	app := SlackApp{
 		opts:              *opts,
+		pool:              &workerPool{slots: make(chan struct{}, workers), queueSize: queueSize},
+		responseURLs:      newResponseURLTracker(opts.Clock),
+		mux:               http.NewServeMux(),
+		signatures:        signatures,
+		eventIDs:          events,
 		distCB:            nil,
-		cmds:              make(map[string]func(ctx *SlackContext)),
-		actionListeners:   make(map[string]func(ctx *SlackContext)),
-		submitListeners:   make(map[string]func(ctx *SlackContext)),
-		closeListeners:    make(map[string]func(ctx *SlackContext)),
-		eventListeners:    make(map[string]func(ctx *SlackContext)),
-		shortcutListeners: make(map[string]func(ctx *SlackContext))}
+		cmds:              make(map[string]Handler),
+		actionListeners:   make(map[string]Handler),
+		submitListeners:   make(map[string]Handler),
+		closeListeners:    make(map[string]Handler),
+		eventListeners:    make(map[string]Handler),
+		optionsListeners:  make(map[string]Handler),
+		homeListeners:     make(map[string]Handler),
+		uninstalls:        &inFlight{keys: make(map[string]bool)},
+		shortcutListeners: make(map[string]Handler)}
 	return app
 }
 
+// Client - Return a Web API client for the token that shares the app's connection pool
+func (a *SlackApp) Client(token string) *Client {
+	return InitializeClient(&ClientOptions{
+		Token:       token,
+		BaseURL:     a.opts.APIBaseURL,
+		HTTPClient:  a.opts.HTTPClient,
+		RateLimiter: a.opts.RateLimiter})
+}
+
 // Response - Send response back to slack
 func Response(ctx *SlackContext, code int, message []byte, headers map[string]string) {
 	for k, v := range headers {
@@ -325,13 +740,224 @@ func ConvertState(state ISlackBlockKitUI, dst interface{}) error {
 	return nil
 }
 
+// handlerFunc - Adapt a handler without error to Handler
+func handlerFunc(handler func(ctx *SlackContext)) Handler {
+	return func(ctx *SlackContext) error {
+		handler(ctx)
+		return nil
+	}
+}
+
+// unrecognized - Error for requests without a registered handler
+func unrecognized(format string, a ...interface{}) error {
+	return &SlackRequestError{Status: http.StatusNotFound, Err: fmt.Errorf(format, a...)}
+}
+
+// run - Run a handler with the app middleware, false when it failed
+func (a *SlackApp) run(ctx *SlackContext, handler Handler) bool {
+	return a.recoverRun(ctx, chain(handler, a.middleware))
+}
+
+// recoverRun - Run a handler, returned errors and recovered panics go to error handling and return false
+func (a *SlackApp) recoverRun(ctx *SlackContext, handler Handler) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.errorHandling(ctx.Res, ctx.Req, &PanicError{Value: r, Stack: debug.Stack()})
+			ok = false
+		}
+	}()
+	if err := handler(ctx); err != nil {
+		a.errorHandling(ctx.Res, ctx.Req, err)
+		return false
+	}
+	return true
+}
+
+// Ack - Middleware that acknowledges the request right away, with payload if not nil,
+// then runs the handler in the app's worker pool. Responses written by the handler are dropped,
+// use the token and response url of the context instead
+func (a *SlackApp) Ack(payload ISlackBlockKitUI) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx *SlackContext) error {
+			var ack []byte
+			if payload != nil {
+				jsonPayload, err := json.Marshal(payload)
+				if err != nil {
+					return err
+				}
+				ack = jsonPayload
+			}
+			background := *ctx
+			background.Context = detachedContext{parent: ctx.Context}
+			background.Res = &asyncResponse{header: make(http.Header)}
+			err := a.pool.submit(func() {
+				a.recoverRun(&background, next)
+			})
+			if err != nil {
+				return err
+			}
+			if ack != nil {
+				Response(ctx, http.StatusOK, ack, map[string]string{
+					"Content-Type": "application/json"})
+			} else {
+				Response(ctx, http.StatusOK, nil, nil)
+			}
+			return nil
+		}
+	}
+}
+
+// chain - Wrap a handler with middleware, the first middleware runs first
+func chain(handler Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// Error - Message of the request error
+func (e *SlackRequestError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap - Underlying error of the request error
+func (e *SlackRequestError) Unwrap() error {
+	return e.Err
+}
+
+// Error - Message and stack trace of the panic
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("Handler panic: %v\n%s", e.Value, e.Stack)
+}
+
+// WriteHeader - Write the status code and remember the response has started
+func (t *responseTracker) WriteHeader(code int) {
+	t.written = true
+	t.ResponseWriter.WriteHeader(code)
+}
+
+// Write - Write the body and remember the response has started
+func (t *responseTracker) Write(b []byte) (int, error) {
+	t.written = true
+	return t.ResponseWriter.Write(b)
+}
+
+// Flush - Flush the response if the underlying writer supports it
+func (t *responseTracker) Flush() {
+	if flusher, ok := t.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // errorHandling - Error handling
 func (a *SlackApp) errorHandling(res http.ResponseWriter, req *http.Request, err error) {
 	if a.errorCB != nil {
 		a.errorCB(res, req, err)
-	} else {
-		log.Println(err.Error())
-		Response(&SlackContext{Res: res}, http.StatusUnauthorized, []byte(err.Error()), nil)
+		return
 	}
-	return
+	log.Println(err.Error())
+	if tracker, ok := res.(*responseTracker); ok && tracker.written {
+		return
+	}
+	status := http.StatusInternalServerError
+	var reqErr *SlackRequestError
+	if errors.As(err, &reqErr) {
+		status = reqErr.Status
+	}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		Response(&SlackContext{Res: res}, status, []byte(http.StatusText(status)), nil)
+		return
+	}
+	Response(&SlackContext{Res: res}, status, []byte(err.Error()), nil)
+}
+
+// submit - Run a job once a worker slot is free, fails with ErrQueueFull when queueSize jobs are waiting already
+func (p *workerPool) submit(job func()) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return errors.New("App is closed")
+	}
+	if p.running >= cap(p.slots)+p.queueSize {
+		return &SlackRequestError{Status: http.StatusServiceUnavailable, Err: ErrQueueFull}
+	}
+	p.running++
+	p.wg.Add(1)
+	go func() {
+		p.slots <- struct{}{}
+		defer func() {
+			<-p.slots
+			p.mu.Lock()
+			p.running--
+			p.mu.Unlock()
+			p.wg.Done()
+		}()
+		job()
+	}()
+	return nil
+}
+
+// drain - Stop accepting jobs and wait for the running ones
+func (p *workerPool) drain(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	running := p.running
+	p.mu.Unlock()
+	if running == 0 {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// acknowledged - Whether the request was already acknowledged by Ack, so writes to Res are dropped
+func (ctx *SlackContext) acknowledged() bool {
+	_, ok := ctx.Res.(*asyncResponse)
+	return ok
 }
+
+// Deadline - No deadline, the handler runs after slack stopped waiting
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done - Never done
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err - Never canceled
+func (c detachedContext) Err() error {
+	return nil
+}
+
+// Value - Value of the request context, e.g. set by middleware
+func (c detachedContext) Value(key interface{}) interface{} {
+	if c.parent == nil {
+		return nil
+	}
+	return c.parent.Value(key)
+}
+
+// Header - Response headers
+func (r *asyncResponse) Header() http.Header {
+	return r.header
+}
+
+// Write - Drop the body, the request has already been acknowledged
+func (r *asyncResponse) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// WriteHeader - Drop the status code, the request has already been acknowledged
+func (r *asyncResponse) WriteHeader(code int) {}
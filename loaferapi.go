@@ -1,6 +1,7 @@
 package loafer
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,203 +9,464 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
-// slackUserQuery - Calls Slack Users.info or Users.lookupByEmail API
-func slackUserCall(uri string, token string) (*SlackUser, error) {
-	var userQuery SlackUsersQuery
-	client := &http.Client{}
-	r, err := http.NewRequest("GET", uri, nil)
-	if err != nil {
-		return nil, err
+// defaultHTTPClient - Connection pool shared by clients without their own http.Client
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// ErrStaleViewHash - The view changed since the hash passed to a views update or publish was read
+var ErrStaleViewHash = errors.New("View hash is stale, the view was updated since it was read")
+
+// InitializeClient - Return a Slack Web API client
+func InitializeClient(opts *ClientOptions) *Client {
+	client := &Client{
+		token:       opts.Token,
+		baseURL:     opts.BaseURL,
+		httpClient:  opts.HTTPClient,
+		userAgent:   opts.UserAgent,
+		maxRetries:  opts.MaxRetries,
+		rateLimiter: opts.RateLimiter}
+	if len(client.baseURL) == 0 {
+		client.baseURL = SLACKAPIURL
 	}
-	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	resp, err := client.Do(r)
-	if err != nil {
-		return nil, err
+	if !strings.HasSuffix(client.baseURL, "/") {
+		client.baseURL += "/"
 	}
-	text, err := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	defer client.CloseIdleConnections()
-	if err != nil {
-		return nil, err
+	if client.httpClient == nil {
+		client.httpClient = defaultHTTPClient
 	}
-	if !strings.Contains(string(text), "\"ok\":true") {
-		return nil, err
+	if client.maxRetries == 0 {
+		client.maxRetries = DEFAULTMAXRETRIES
 	}
-	err = json.NewDecoder(strings.NewReader(string(text))).Decode(&userQuery)
-	if err != nil {
-		return nil, err
+	return client
+}
+
+// defaultClient - Client used by the package level API calls
+func defaultClient(token string) *Client {
+	return InitializeClient(&ClientOptions{Token: token})
+}
+
+// call - Calls a Slack Web API method with a form and decodes the response into dst
+func (c *Client) call(ctx context.Context, method string, form url.Values, dst interface{}) error {
+	key := c.rateLimitKey(method, form)
+	if c.rateLimiter != nil {
+		if err := sleepContext(ctx, c.rateLimiter.reserve(key, method)); err != nil {
+			return err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, form)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < c.maxRetries {
+			resp.Body.Close()
+			wait := retryAfter(resp, attempt)
+			if c.rateLimiter != nil {
+				c.rateLimiter.delay(key, wait)
+			}
+			if err := sleepContext(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+		defer resp.Body.Close()
+		return decodeResponse(method, resp, dst)
 	}
-	return &userQuery.User, nil
 }
 
-// slackMessageCall - Calls Slack Chat.PostMessage API
-func slackMessageCall(uri string, form url.Values, token string) error {
-	client := &http.Client{}
-	r, err := http.NewRequest("POST", uri, strings.NewReader(form.Encode()))
+// send - Send a single Web API request
+func (c *Client) send(ctx context.Context, method string, form url.Values) (*http.Response, error) {
+	r, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+method, strings.NewReader(form.Encode()))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	resp, err := client.Do(r)
-	if err != nil {
-		return err
+	if len(c.token) > 0 {
+		r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 	}
-	bodyText, err := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	defer client.CloseIdleConnections()
-	if err != nil {
-		return err
-	}
-	if !strings.Contains(string(bodyText), "\"ok\":true") {
-		return errors.New(string(bodyText))
+	if len(c.userAgent) > 0 {
+		r.Header.Set("User-Agent", c.userAgent)
 	}
-	return nil
+	return c.httpClient.Do(r)
 }
 
-// OpenView - Open view in slack
-func OpenView(view SlackModal, triggerID string, token string) error {
-	jsonView, err := json.Marshal(view)
-	if err != nil {
-		return err
+// sleepContext - Sleep unless the context is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
 	}
-	form := url.Values{
-		"view":       []string{string(jsonView)},
-		"trigger_id": []string{triggerID}}
-	client := &http.Client{}
-	r, err := http.NewRequest("POST", "https://slack.com/api/views.open", strings.NewReader(form.Encode()))
-	if err != nil {
-		return err
-	}
-	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	resp, err := client.Do(r)
-	if err != nil {
-		return err
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
+}
+
+// decodeResponse - Decode a Web API response into dst, returns a SlackAPIError when it is not ok
+func decodeResponse(method string, resp *http.Response, dst interface{}) error {
 	bodyText, err := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	defer client.CloseIdleConnections()
 	if err != nil {
 		return err
 	}
-	if !strings.Contains(string(bodyText), "\"ok\":true") {
-		fmt.Println(string(bodyText))
-		return errors.New(string(bodyText))
+	var status struct {
+		Ok bool `json:"ok"`
+		SlackAPIError
+	}
+	err = json.Unmarshal(bodyText, &status)
+	if err != nil || !status.Ok {
+		apiErr := status.SlackAPIError
+		apiErr.Method = method
+		apiErr.StatusCode = resp.StatusCode
+		if err != nil && resp.StatusCode < http.StatusMultipleChoices {
+			apiErr.Code = "invalid_response"
+		}
+		return &apiErr
+	}
+	if dst != nil {
+		return json.Unmarshal(bodyText, dst)
 	}
 	return nil
 }
 
-// UpdateView - Update a view in slack
-func UpdateView(view SlackInteractionView, viewID string, token string) error {
-	jsonView, err := json.Marshal(view)
-	if err != nil {
-		return err
+// Error - Describe the failed Slack API call
+func (e *SlackAPIError) Error() string {
+	code := e.Code
+	if len(code) == 0 {
+		code = fmt.Sprintf("http status %d", e.StatusCode)
 	}
-	form := url.Values{
-		"view":    []string{string(jsonView)},
-		"view_id": []string{viewID}}
-	client := &http.Client{}
-	r, err := http.NewRequest("POST", "https://slack.com/api/views.update", strings.NewReader(form.Encode()))
+	if len(e.ResponseMetadata.Messages) > 0 {
+		return fmt.Sprintf("Slack API %s failed: %s (%s)", e.Method, code, strings.Join(e.ResponseMetadata.Messages, ", "))
+	}
+	return fmt.Sprintf("Slack API %s failed: %s", e.Method, code)
+}
+
+// Is - Match ErrStaleViewHash for hash_conflict errors, e.g. errors.Is(err, ErrStaleViewHash)
+func (e *SlackAPIError) Is(target error) bool {
+	return target == ErrStaleViewHash && e.Code == "hash_conflict"
+}
+
+// slackUserCall - Calls Slack Users.info or Users.lookupByEmail API
+func (c *Client) slackUserCall(ctx context.Context, method string, form url.Values) (*SlackUser, error) {
+	var userQuery SlackUsersQuery
+	err := c.call(ctx, method, form, &userQuery)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	resp, err := client.Do(r)
+	return &userQuery.User, nil
+}
+
+// OpenView - Open view in slack
+func (c *Client) OpenView(view SlackModal, triggerID string) error {
+	return c.OpenViewContext(context.Background(), view, triggerID)
+}
+
+// OpenViewContext - Open view in slack, canceled with ctx
+func (c *Client) OpenViewContext(ctx context.Context, view SlackModal, triggerID string) error {
+	_, err := c.OpenViewWithResultContext(ctx, view, triggerID)
+	return err
+}
+
+// OpenViewWithResult - Open view in slack and return it, with the id and hash to update it with
+func (c *Client) OpenViewWithResult(view SlackModal, triggerID string) (*SlackInteractionView, error) {
+	return c.OpenViewWithResultContext(context.Background(), view, triggerID)
+}
+
+// OpenViewWithResultContext - Open view in slack and return it, canceled with ctx
+func (c *Client) OpenViewWithResultContext(ctx context.Context, view SlackModal, triggerID string) (*SlackInteractionView, error) {
+	return c.slackViewCall(ctx, "views.open", view, url.Values{"trigger_id": []string{triggerID}})
+}
+
+// slackViewCall - Calls a Slack views API with a view and returns the resulting view
+func (c *Client) slackViewCall(ctx context.Context, method string, view ISlackBlockKitUI, form url.Values) (*SlackInteractionView, error) {
+	jsonView, err := json.Marshal(view)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	bodyText, err := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	defer client.CloseIdleConnections()
+	form.Set("view", string(jsonView))
+	var viewQuery struct {
+		View SlackInteractionView `json:"view"`
+	}
+	err = c.call(ctx, method, form, &viewQuery)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !strings.Contains(string(bodyText), "\"ok\":true") {
-		return errors.New(string(bodyText))
+	return &viewQuery.View, nil
+}
+
+// PushView - Push a modal on top of the open one
+func (c *Client) PushView(view SlackModal, triggerID string) (*SlackInteractionView, error) {
+	return c.PushViewContext(context.Background(), view, triggerID)
+}
+
+// PushViewContext - Push a modal on top of the open one, canceled with ctx
+func (c *Client) PushViewContext(ctx context.Context, view SlackModal, triggerID string) (*SlackInteractionView, error) {
+	return c.slackViewCall(ctx, "views.push", view, url.Values{"trigger_id": []string{triggerID}})
+}
+
+// UpdateView - Update a view in slack, view is a SlackModal, SlackHomeView or SlackInteractionView
+func (c *Client) UpdateView(view ISlackBlockKitUI, viewID string) error {
+	return c.UpdateViewContext(context.Background(), view, viewID)
+}
+
+// UpdateViewContext - Update a view in slack, canceled with ctx
+func (c *Client) UpdateViewContext(ctx context.Context, view ISlackBlockKitUI, viewID string) error {
+	_, err := c.UpdateViewWithResultContext(ctx, view, viewID)
+	return err
+}
+
+// UpdateViewWithResult - Update a view in slack and return it, with the new hash for the next update
+func (c *Client) UpdateViewWithResult(view ISlackBlockKitUI, viewID string) (*SlackInteractionView, error) {
+	return c.UpdateViewWithResultContext(context.Background(), view, viewID)
+}
+
+// UpdateViewWithResultContext - Update a view in slack and return it, canceled with ctx
+func (c *Client) UpdateViewWithResultContext(ctx context.Context, view ISlackBlockKitUI, viewID string) (*SlackInteractionView, error) {
+	return c.slackViewCall(ctx, "views.update", view, url.Values{"view_id": []string{viewID}})
+}
+
+// UpdateViewWithHash - Update a view unless it changed since hash was read, fails with ErrStaleViewHash otherwise
+func (c *Client) UpdateViewWithHash(view ISlackBlockKitUI, viewID string, hash string) (*SlackInteractionView, error) {
+	return c.UpdateViewWithHashContext(context.Background(), view, viewID, hash)
+}
+
+// UpdateViewWithHashContext - Update a view unless it changed since hash was read, canceled with ctx
+func (c *Client) UpdateViewWithHashContext(ctx context.Context, view ISlackBlockKitUI, viewID string, hash string) (*SlackInteractionView, error) {
+	return c.slackViewCall(ctx, "views.update", view, url.Values{
+		"view_id": []string{viewID},
+		"hash":    []string{hash}})
+}
+
+// UpdateViewByExternalID - Update the view opened with an external_id
+func (c *Client) UpdateViewByExternalID(view ISlackBlockKitUI, externalID string) (*SlackInteractionView, error) {
+	return c.UpdateViewByExternalIDContext(context.Background(), view, externalID)
+}
+
+// UpdateViewByExternalIDContext - Update the view opened with an external_id, canceled with ctx
+func (c *Client) UpdateViewByExternalIDContext(ctx context.Context, view ISlackBlockKitUI, externalID string) (*SlackInteractionView, error) {
+	return c.slackViewCall(ctx, "views.update", view, url.Values{"external_id": []string{externalID}})
+}
+
+// PublishView - Publish the Home tab of a user, an empty hash publishes unconditionally, fails with ErrStaleViewHash when hash is stale
+func (c *Client) PublishView(view ISlackBlockKitUI, userID string, hash string) (*SlackInteractionView, error) {
+	return c.PublishViewContext(context.Background(), view, userID, hash)
+}
+
+// PublishViewContext - Publish the Home tab of a user, canceled with ctx
+func (c *Client) PublishViewContext(ctx context.Context, view ISlackBlockKitUI, userID string, hash string) (*SlackInteractionView, error) {
+	form := url.Values{"user_id": []string{userID}}
+	if len(hash) > 0 {
+		form.Set("hash", hash)
 	}
-	return nil
+	return c.slackViewCall(ctx, "views.publish", view, form)
 }
 
 // FindUserByEmail - Finding slack user by email
-func FindUserByEmail(email string, token string) (*SlackUser, error) {
-	return slackUserCall(fmt.Sprintf("https://slack.com/api/users.lookupByEmail?email=%s&token=%s", email, token), token)
+func (c *Client) FindUserByEmail(email string) (*SlackUser, error) {
+	return c.FindUserByEmailContext(context.Background(), email)
+}
+
+// FindUserByEmailContext - Finding slack user by email, canceled with ctx
+func (c *Client) FindUserByEmailContext(ctx context.Context, email string) (*SlackUser, error) {
+	return c.slackUserCall(ctx, "users.lookupByEmail", url.Values{"email": []string{email}})
 }
 
 // FindUserByID - Finding slack user by id
-func FindUserByID(id string, token string) (*SlackUser, error) {
-	return slackUserCall(fmt.Sprintf("https://slack.com/api/users.info?user=%s&token=%s", id, token), token)
+func (c *Client) FindUserByID(id string) (*SlackUser, error) {
+	return c.FindUserByIDContext(context.Background(), id)
+}
+
+// FindUserByIDContext - Finding slack user by id, canceled with ctx
+func (c *Client) FindUserByIDContext(ctx context.Context, id string) (*SlackUser, error) {
+	return c.slackUserCall(ctx, "users.info", url.Values{"user": []string{id}})
 }
 
 // UpdateMessage - Update a slack message
-func UpdateMessage(channel string, ts string, blocks ISlackBlockKitUI, text string, token string) error {
-	var jsonBlocks []byte
-	var err error
-	if blocks != nil {
-		jsonBlocks, err = json.Marshal(blocks)
-		if err != nil {
-			return err
-		}
-	}
+func (c *Client) UpdateMessage(channel string, ts string, blocks ISlackBlockKitUI, text string) error {
+	return c.UpdateMessageContext(context.Background(), channel, ts, blocks, text)
+}
+
+// UpdateMessageContext - Update a slack message, canceled with ctx
+func (c *Client) UpdateMessageContext(ctx context.Context, channel string, ts string, blocks ISlackBlockKitUI, text string) error {
 	form := url.Values{
 		"channel": []string{channel},
 		"ts":      []string{ts},
 		"text":    []string{text}}
 	if blocks != nil {
+		jsonBlocks, err := json.Marshal(blocks)
+		if err != nil {
+			return err
+		}
 		form.Set("blocks", string(jsonBlocks))
 	}
-	return slackMessageCall("https://slack.com/api/chat.update", form, token)
+	return c.call(ctx, "chat.update", form, nil)
 }
 
 // PostMessage - Post a message
-func PostMessage(channel string, blocks ISlackBlockKitUI, text string, token string) error {
-	var jsonBlocks []byte
-	var err error
-	if blocks != nil {
-		jsonBlocks, err = json.Marshal(blocks)
-		if err != nil {
-			return err
-		}
-	}
+func (c *Client) PostMessage(channel string, blocks ISlackBlockKitUI, text string) error {
+	return c.PostMessageContext(context.Background(), channel, blocks, text)
+}
+
+// PostMessageContext - Post a message, canceled with ctx
+func (c *Client) PostMessageContext(ctx context.Context, channel string, blocks ISlackBlockKitUI, text string) error {
 	form := url.Values{
 		"channel": []string{channel},
 		"text":    []string{text}}
 	if blocks != nil {
+		jsonBlocks, err := json.Marshal(blocks)
+		if err != nil {
+			return err
+		}
 		form.Set("blocks", string(jsonBlocks))
 	}
-	return slackMessageCall("https://slack.com/api/chat.postMessage", form, token)
+	return c.call(ctx, "chat.postMessage", form, nil)
 }
 
 // FileUpload - Upload a file
-func FileUpload(channels []string, filename string, content string, filetype string, token string) error {
+func (c *Client) FileUpload(channels []string, filename string, content string, filetype string) error {
+	return c.FileUploadContext(context.Background(), channels, filename, content, filetype)
+}
+
+// FileUploadContext - Upload a file, canceled with ctx
+func (c *Client) FileUploadContext(ctx context.Context, channels []string, filename string, content string, filetype string) error {
 	form := url.Values{
 		"content":  []string{content},
 		"filename": []string{filename},
 		"filetype": []string{filetype},
 		"channels": []string{strings.Join(channels, ",")}}
-	client := &http.Client{}
-	r, err := http.NewRequest("POST", "https://slack.com/api/files.upload", strings.NewReader(form.Encode()))
-	if err != nil {
-		return err
-	}
-	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	resp, err := client.Do(r)
-	if err != nil {
-		return err
-	}
-	text, err := ioutil.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	defer client.CloseIdleConnections()
-	if err != nil {
-		return err
-	}
-	if !strings.Contains(string(text), "\"ok\":true") {
-		return err
-	}
-	return nil
+	return c.call(ctx, "files.upload", form, nil)
+}
+
+// OpenView - Open view in slack
+func OpenView(view SlackModal, triggerID string, token string) error {
+	return defaultClient(token).OpenView(view, triggerID)
+}
+
+// OpenViewContext - Open view in slack, canceled with ctx
+func OpenViewContext(ctx context.Context, view SlackModal, triggerID string, token string) error {
+	return defaultClient(token).OpenViewContext(ctx, view, triggerID)
+}
+
+// OpenViewWithResult - Open view in slack and return it, with the id and hash to update it with
+func OpenViewWithResult(view SlackModal, triggerID string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).OpenViewWithResult(view, triggerID)
+}
+
+// OpenViewWithResultContext - Open view in slack and return it, canceled with ctx
+func OpenViewWithResultContext(ctx context.Context, view SlackModal, triggerID string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).OpenViewWithResultContext(ctx, view, triggerID)
+}
+
+// PushView - Push a modal on top of the open one
+func PushView(view SlackModal, triggerID string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).PushView(view, triggerID)
+}
+
+// PushViewContext - Push a modal on top of the open one, canceled with ctx
+func PushViewContext(ctx context.Context, view SlackModal, triggerID string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).PushViewContext(ctx, view, triggerID)
+}
+
+// UpdateView - Update a view in slack, view is a SlackModal, SlackHomeView or SlackInteractionView
+func UpdateView(view ISlackBlockKitUI, viewID string, token string) error {
+	return defaultClient(token).UpdateView(view, viewID)
+}
+
+// UpdateViewContext - Update a view in slack, canceled with ctx
+func UpdateViewContext(ctx context.Context, view ISlackBlockKitUI, viewID string, token string) error {
+	return defaultClient(token).UpdateViewContext(ctx, view, viewID)
+}
+
+// UpdateViewWithResult - Update a view in slack and return it, with the new hash for the next update
+func UpdateViewWithResult(view ISlackBlockKitUI, viewID string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).UpdateViewWithResult(view, viewID)
+}
+
+// UpdateViewWithResultContext - Update a view in slack and return it, canceled with ctx
+func UpdateViewWithResultContext(ctx context.Context, view ISlackBlockKitUI, viewID string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).UpdateViewWithResultContext(ctx, view, viewID)
+}
+
+// UpdateViewWithHash - Update a view unless it changed since hash was read, fails with ErrStaleViewHash otherwise
+func UpdateViewWithHash(view ISlackBlockKitUI, viewID string, hash string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).UpdateViewWithHash(view, viewID, hash)
+}
+
+// UpdateViewWithHashContext - Update a view unless it changed since hash was read, canceled with ctx
+func UpdateViewWithHashContext(ctx context.Context, view ISlackBlockKitUI, viewID string, hash string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).UpdateViewWithHashContext(ctx, view, viewID, hash)
+}
+
+// UpdateViewByExternalID - Update the view opened with an external_id
+func UpdateViewByExternalID(view ISlackBlockKitUI, externalID string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).UpdateViewByExternalID(view, externalID)
+}
+
+// UpdateViewByExternalIDContext - Update the view opened with an external_id, canceled with ctx
+func UpdateViewByExternalIDContext(ctx context.Context, view ISlackBlockKitUI, externalID string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).UpdateViewByExternalIDContext(ctx, view, externalID)
+}
+
+// PublishView - Publish the Home tab of a user, an empty hash publishes unconditionally
+func PublishView(view ISlackBlockKitUI, userID string, hash string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).PublishView(view, userID, hash)
+}
+
+// PublishViewContext - Publish the Home tab of a user, canceled with ctx
+func PublishViewContext(ctx context.Context, view ISlackBlockKitUI, userID string, hash string, token string) (*SlackInteractionView, error) {
+	return defaultClient(token).PublishViewContext(ctx, view, userID, hash)
+}
+
+// FindUserByEmail - Finding slack user by email
+func FindUserByEmail(email string, token string) (*SlackUser, error) {
+	return defaultClient(token).FindUserByEmail(email)
+}
+
+// FindUserByEmailContext - Finding slack user by email, canceled with ctx
+func FindUserByEmailContext(ctx context.Context, email string, token string) (*SlackUser, error) {
+	return defaultClient(token).FindUserByEmailContext(ctx, email)
+}
+
+// FindUserByID - Finding slack user by id
+func FindUserByID(id string, token string) (*SlackUser, error) {
+	return defaultClient(token).FindUserByID(id)
+}
+
+// FindUserByIDContext - Finding slack user by id, canceled with ctx
+func FindUserByIDContext(ctx context.Context, id string, token string) (*SlackUser, error) {
+	return defaultClient(token).FindUserByIDContext(ctx, id)
+}
+
+// UpdateMessage - Update a slack message
+func UpdateMessage(channel string, ts string, blocks ISlackBlockKitUI, text string, token string) error {
+	return defaultClient(token).UpdateMessage(channel, ts, blocks, text)
+}
+
+// UpdateMessageContext - Update a slack message, canceled with ctx
+func UpdateMessageContext(ctx context.Context, channel string, ts string, blocks ISlackBlockKitUI, text string, token string) error {
+	return defaultClient(token).UpdateMessageContext(ctx, channel, ts, blocks, text)
+}
+
+// PostMessage - Post a message
+func PostMessage(channel string, blocks ISlackBlockKitUI, text string, token string) error {
+	return defaultClient(token).PostMessage(channel, blocks, text)
+}
+
+// PostMessageContext - Post a message, canceled with ctx
+func PostMessageContext(ctx context.Context, channel string, blocks ISlackBlockKitUI, text string, token string) error {
+	return defaultClient(token).PostMessageContext(ctx, channel, blocks, text)
+}
+
+// FileUpload - Upload a file
+func FileUpload(channels []string, filename string, content string, filetype string, token string) error {
+	return defaultClient(token).FileUpload(channels, filename, content, filetype)
+}
+
+// FileUploadContext - Upload a file, canceled with ctx
+func FileUploadContext(ctx context.Context, channels []string, filename string, content string, filetype string, token string) error {
+	return defaultClient(token).FileUploadContext(ctx, channels, filename, content, filetype)
 }
@@ -0,0 +1,326 @@
+package loafer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	userMention    = regexp.MustCompile(`^<@([UW][A-Z0-9]+)(?:\|([^>]*))?>$`)
+	channelMention = regexp.MustCompile(`^<#([CG][A-Z0-9]+)(?:\|([^>]*))?>$`)
+	// slack escapes &, < and > in command text, mentions keep their escaped <...> form
+	commandUnescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+)
+
+// InitializeCommandRouter - Return a router for the subcommands of a slash command
+func InitializeCommandRouter(command string) *CommandRouter {
+	return &CommandRouter{
+		command:     command,
+		subcommands: make(map[string]*Subcommand)}
+}
+
+// Add - Add a subcommand, its arguments are parsed in order from the text after the subcommand name
+func (r *CommandRouter) Add(name string, description string, args []CommandArg, handler func(ctx *SlackContext, args *CommandArgs) error) {
+	if _, ok := r.subcommands[name]; !ok {
+		r.order = append(r.order, name)
+	}
+	r.subcommands[name] = &Subcommand{
+		Name:        name,
+		Description: description,
+		Args:        args,
+		Handler:     handler}
+}
+
+// Handle - Handler routing the command text to its subcommand, register it with HandleCommand
+func (r *CommandRouter) Handle(ctx *SlackContext) error {
+	text := ""
+	if ctx.Command != nil {
+		text = ctx.Command.Text
+	}
+	words, err := SplitCommandText(text)
+	if err != nil {
+		return respondCommandError(ctx, err.Error())
+	}
+	if len(words) == 0 || words[0] == "help" {
+		return respondCommandHelp(ctx, r.help())
+	}
+	sub, ok := r.subcommands[words[0]]
+	if !ok {
+		message := fmt.Sprintf("Unknown subcommand `%s`.", words[0])
+		if suggestion := r.suggest(words[0]); len(suggestion) > 0 {
+			message += fmt.Sprintf(" Did you mean `%s %s`?", r.command, suggestion)
+		}
+		message += fmt.Sprintf(" Try `%s help` for the list of subcommands.", r.command)
+		return respondCommandError(ctx, message)
+	}
+	args, err := sub.parse(words[1:])
+	if err != nil {
+		return respondCommandError(ctx, fmt.Sprintf("%s\nUsage: `%s`", err.Error(), sub.usage(r.command)))
+	}
+	return sub.Handler(ctx, args)
+}
+
+// help - Blocks listing every subcommand
+func (r *CommandRouter) help() []ISlackBlockKitUI {
+	blocks := []ISlackBlockKitUI{MakeSlackTextSection(fmt.Sprintf("*%s* subcommands:", r.command))}
+	for _, name := range r.order {
+		sub := r.subcommands[name]
+		blocks = append(blocks, MakeSlackTextSection(fmt.Sprintf("`%s`\n%s", sub.usage(r.command), sub.Description)))
+	}
+	return blocks
+}
+
+// suggest - Closest subcommand name to an unknown one, empty if none is close enough
+func (r *CommandRouter) suggest(name string) string {
+	best, bestDistance := "", 3
+	names := append([]string{}, r.order...)
+	sort.Strings(names)
+	for _, candidate := range names {
+		distance := editDistance(name, candidate)
+		if strings.HasPrefix(candidate, name) {
+			distance = 1
+		}
+		if distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// usage - Usage line of a subcommand
+func (s *Subcommand) usage(command string) string {
+	parts := []string{command, s.Name}
+	for _, arg := range s.Args {
+		name := arg.Name
+		if arg.Type == ARGENUM {
+			name = strings.Join(arg.Values, "|")
+		}
+		if arg.Optional {
+			parts = append(parts, "["+name+"]")
+		} else {
+			parts = append(parts, "<"+name+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// parse - Parse the words after the subcommand name into its arguments
+func (s *Subcommand) parse(words []string) (*CommandArgs, error) {
+	args := &CommandArgs{values: make(map[string]interface{})}
+	if len(words) > len(s.Args) {
+		return nil, fmt.Errorf("Too many arguments, expected at most %d", len(s.Args))
+	}
+	for i, arg := range s.Args {
+		if i >= len(words) {
+			if !arg.Optional {
+				return nil, fmt.Errorf("Missing argument <%s>", arg.Name)
+			}
+			continue
+		}
+		value, err := arg.parse(words[i])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid argument <%s>: %s", arg.Name, err.Error())
+		}
+		args.values[arg.Name] = value
+	}
+	return args, nil
+}
+
+// parse - Parse a word into the type of the argument
+func (arg CommandArg) parse(word string) (interface{}, error) {
+	switch arg.Type {
+	case ARGINT:
+		return strconv.Atoi(word)
+	case ARGDURATION:
+		return parseDuration(word)
+	case ARGUSER:
+		match := userMention.FindStringSubmatch(word)
+		if match == nil {
+			return nil, fmt.Errorf("`%s` is not a user mention", word)
+		}
+		return SlackMention{ID: match[1], Name: match[2]}, nil
+	case ARGCHANNEL:
+		match := channelMention.FindStringSubmatch(word)
+		if match == nil {
+			return nil, fmt.Errorf("`%s` is not a channel mention", word)
+		}
+		return SlackMention{ID: match[1], Name: match[2]}, nil
+	case ARGENUM:
+		word = commandUnescaper.Replace(word)
+		for _, value := range arg.Values {
+			if strings.EqualFold(value, word) {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("`%s` is not one of %s", word, strings.Join(arg.Values, ", "))
+	default:
+		return commandUnescaper.Replace(word), nil
+	}
+}
+
+// parseDuration - Parse a duration, also accepting days such as 2d
+func parseDuration(word string) (time.Duration, error) {
+	if strings.HasSuffix(word, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(word, "d"))
+		if err == nil {
+			return time.Duration(days) * 24 * time.Hour, nil
+		}
+	}
+	duration, err := time.ParseDuration(word)
+	if err != nil {
+		return 0, fmt.Errorf("`%s` is not a duration such as 30m or 1h30m", word)
+	}
+	return duration, nil
+}
+
+// Has - Whether an optional argument was given
+func (c *CommandArgs) Has(name string) bool {
+	_, ok := c.values[name]
+	return ok
+}
+
+// String - Value of a string or enum argument
+func (c *CommandArgs) String(name string) string {
+	value, _ := c.values[name].(string)
+	return value
+}
+
+// Int - Value of an int argument
+func (c *CommandArgs) Int(name string) int {
+	value, _ := c.values[name].(int)
+	return value
+}
+
+// Duration - Value of a duration argument
+func (c *CommandArgs) Duration(name string) time.Duration {
+	value, _ := c.values[name].(time.Duration)
+	return value
+}
+
+// User - Value of a user mention argument
+func (c *CommandArgs) User(name string) SlackMention {
+	value, _ := c.values[name].(SlackMention)
+	return value
+}
+
+// Channel - Value of a channel mention argument
+func (c *CommandArgs) Channel(name string) SlackMention {
+	value, _ := c.values[name].(SlackMention)
+	return value
+}
+
+// SplitCommandText - Split command text into words, honoring single, double and smart quotes and backslash escapes
+func SplitCommandText(text string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord, escaped := false, false
+	var quote rune
+	for _, c := range text {
+		switch {
+		case escaped:
+			word.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if closesQuote(quote, c) {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '"' || c == '\'' || c == '“' || c == '‘':
+			quote, inWord = c, true
+		case unicode.IsSpace(c):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("Missing closing quote")
+	}
+	if escaped {
+		word.WriteRune('\\')
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// closesQuote - Whether c closes the quote opened with open
+func closesQuote(open rune, c rune) bool {
+	switch open {
+	case '“':
+		return c == '”' || c == '“'
+	case '‘':
+		return c == '’' || c == '‘'
+	default:
+		return c == open
+	}
+}
+
+// editDistance - Levenshtein distance between two words
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current := make([]int, len(rb)+1)
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(rb)]
+}
+
+// minInt - Smaller of two ints
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// respondCommandError - Reply to the command with an ephemeral error message
+func respondCommandError(ctx *SlackContext, message string) error {
+	return respondCommandHelp(ctx, []ISlackBlockKitUI{MakeSlackTextSection(message)})
+}
+
+// respondCommandHelp - Reply to the command with ephemeral blocks, through the response_url once the command was acknowledged by Ack
+func respondCommandHelp(ctx *SlackContext, blocks []ISlackBlockKitUI) error {
+	message := SlackResponseMessage{
+		ResponseType: "ephemeral",
+		Blocks:       blocks}
+	if ctx.acknowledged() {
+		return ctx.Respond(message)
+	}
+	jsonMessage, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	Response(ctx, http.StatusOK, jsonMessage, map[string]string{
+		"Content-Type": "application/json"})
+	return nil
+}
@@ -0,0 +1,105 @@
+package loafer
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoUser - The request has no user to publish a Home tab for
+var ErrNoUser = errors.New("Request has no user")
+
+// Action - Action that triggered a block_actions interaction, nil for other requests
+func (ctx *SlackContext) Action() *SlackInteractionAction {
+	if ctx.Interaction == nil || len(ctx.Interaction.Actions) == 0 {
+		return nil
+	}
+	return &ctx.Interaction.Actions[0]
+}
+
+// User - User who sent the interaction, command or event, nil for other requests
+func (ctx *SlackContext) User() *SlackInteractionUser {
+	if ctx.Interaction != nil {
+		return ctx.Interaction.User
+	}
+	if ctx.Command != nil {
+		return &SlackInteractionUser{
+			ID:       ctx.Command.UserID,
+			Username: ctx.Command.UserName,
+			TeamID:   ctx.Command.TeamID}
+	}
+	if ctx.Event != nil && len(ctx.Event.Event.User) > 0 {
+		return &SlackInteractionUser{
+			ID:     ctx.Event.Event.User,
+			TeamID: ctx.Event.TeamID}
+	}
+	return nil
+}
+
+// Channel - Channel the interaction, command or event was sent from, nil when it didn't come from a channel
+func (ctx *SlackContext) Channel() *SlackInteractionChannel {
+	if ctx.Interaction != nil {
+		if ctx.Interaction.Channel != nil {
+			return ctx.Interaction.Channel
+		}
+		if container := ctx.Interaction.Container; container != nil && len(container.ChannelID) > 0 {
+			return &SlackInteractionChannel{ID: container.ChannelID}
+		}
+		return nil
+	}
+	if ctx.Command != nil {
+		return &SlackInteractionChannel{
+			ID:   ctx.Command.ChannelID,
+			Name: ctx.Command.ChannelName}
+	}
+	if ctx.Event != nil && len(ctx.Event.Event.Channel) > 0 {
+		return &SlackInteractionChannel{ID: ctx.Event.Event.Channel}
+	}
+	return nil
+}
+
+// Message - Message the interaction came from, nil when it didn't come from a message
+func (ctx *SlackContext) Message() *SlackInteractionMessage {
+	if ctx.Interaction == nil {
+		return nil
+	}
+	return ctx.Interaction.Message
+}
+
+// View - View the interaction came from or the Home tab of an app_home_opened event, nil otherwise
+func (ctx *SlackContext) View() *SlackInteractionView {
+	if ctx.Interaction != nil {
+		return ctx.Interaction.View
+	}
+	if ctx.Event != nil {
+		return ctx.Event.Event.View
+	}
+	return nil
+}
+
+// TriggerID - Trigger id to open a view with, empty when the request has none
+func (ctx *SlackContext) TriggerID() string {
+	if ctx.Interaction != nil {
+		return ctx.Interaction.TriggerID
+	}
+	if ctx.Command != nil {
+		return ctx.Command.TriggerID
+	}
+	return ""
+}
+
+// PublishHome - Publish the Home tab of the user who sent the request
+func (ctx *SlackContext) PublishHome(view SlackHomeView) (*SlackInteractionView, error) {
+	user := ctx.User()
+	if user == nil || len(user.ID) == 0 {
+		return nil, ErrNoUser
+	}
+	client := defaultClient(ctx.Token)
+	if ctx.app != nil {
+		client = ctx.app.Client(ctx.Token)
+	}
+	reqCtx := ctx.Context
+	if reqCtx == nil {
+		reqCtx = context.Background()
+	}
+	return client.PublishViewContext(reqCtx, view, user.ID, "")
+}
@@ -0,0 +1,139 @@
+package loafer
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrNoEvent - The request is not an Events API request
+var ErrNoEvent = errors.New("Request has no event")
+
+// UnmarshalJSON - Decode the common fields of an event and keep the whole event in Raw
+func (e *SlackSubscriptionEvent) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		Type    string                `json:"type"`
+		Subtype string                `json:"subtype"`
+		EventTS string                `json:"event_ts"`
+		User    json.RawMessage       `json:"user"`
+		Channel json.RawMessage       `json:"channel"`
+		Tab     string                `json:"tab"`
+		View    *SlackInteractionView `json:"view"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*e = SlackSubscriptionEvent{
+		Type:    fields.Type,
+		Subtype: fields.Subtype,
+		EventTS: fields.EventTS,
+		Tab:     fields.Tab,
+		View:    fields.View,
+		Raw:     append(json.RawMessage{}, data...)}
+	// team_join and channel_created send objects instead of ids, those are only in Raw
+	json.Unmarshal(fields.User, &e.User)
+	json.Unmarshal(fields.Channel, &e.Channel)
+	return nil
+}
+
+// DecodeEvent - Decode the event of an Events API request into dst, e.g. a *SlackMessageEvent
+func (ctx *SlackContext) DecodeEvent(dst interface{}) error {
+	if ctx.Event == nil || len(ctx.Event.Event.Raw) == 0 {
+		return ErrNoEvent
+	}
+	return json.Unmarshal(ctx.Event.Event.Raw, dst)
+}
+
+// OnMessage - Add handler to message events of a subtype, an empty subtype matches messages without a handler for their subtype
+func (a *SlackApp) OnMessage(subtype string, handler func(ctx *SlackContext, event *SlackMessageEvent) error, middleware ...Middleware) {
+	eventType := "message"
+	if len(subtype) > 0 {
+		eventType += "." + subtype
+	}
+	a.HandleEvent(eventType, decodedHandler(func() interface{} { return &SlackMessageEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackMessageEvent))
+	}), middleware...)
+}
+
+// OnAppMention - Add handler to app_mention events
+func (a *SlackApp) OnAppMention(handler func(ctx *SlackContext, event *SlackAppMentionEvent) error, middleware ...Middleware) {
+	a.HandleEvent("app_mention", decodedHandler(func() interface{} { return &SlackAppMentionEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackAppMentionEvent))
+	}), middleware...)
+}
+
+// OnReactionAdded - Add handler to reaction_added events
+func (a *SlackApp) OnReactionAdded(handler func(ctx *SlackContext, event *SlackReactionEvent) error, middleware ...Middleware) {
+	a.HandleEvent("reaction_added", reactionHandler(handler), middleware...)
+}
+
+// OnReactionRemoved - Add handler to reaction_removed events
+func (a *SlackApp) OnReactionRemoved(handler func(ctx *SlackContext, event *SlackReactionEvent) error, middleware ...Middleware) {
+	a.HandleEvent("reaction_removed", reactionHandler(handler), middleware...)
+}
+
+// reactionHandler - Handler decoding reaction events
+func reactionHandler(handler func(ctx *SlackContext, event *SlackReactionEvent) error) Handler {
+	return decodedHandler(func() interface{} { return &SlackReactionEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackReactionEvent))
+	})
+}
+
+// OnMemberJoinedChannel - Add handler to member_joined_channel events
+func (a *SlackApp) OnMemberJoinedChannel(handler func(ctx *SlackContext, event *SlackMemberJoinedChannelEvent) error, middleware ...Middleware) {
+	a.HandleEvent("member_joined_channel", decodedHandler(func() interface{} { return &SlackMemberJoinedChannelEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackMemberJoinedChannelEvent))
+	}), middleware...)
+}
+
+// OnChannelCreated - Add handler to channel_created events
+func (a *SlackApp) OnChannelCreated(handler func(ctx *SlackContext, event *SlackChannelCreatedEvent) error, middleware ...Middleware) {
+	a.HandleEvent("channel_created", decodedHandler(func() interface{} { return &SlackChannelCreatedEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackChannelCreatedEvent))
+	}), middleware...)
+}
+
+// OnTeamJoin - Add handler to team_join events
+func (a *SlackApp) OnTeamJoin(handler func(ctx *SlackContext, event *SlackTeamJoinEvent) error, middleware ...Middleware) {
+	a.HandleEvent("team_join", decodedHandler(func() interface{} { return &SlackTeamJoinEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackTeamJoinEvent))
+	}), middleware...)
+}
+
+// OnAppUninstalled - Add handler to app_uninstalled events
+func (a *SlackApp) OnAppUninstalled(handler func(ctx *SlackContext, event *SlackAppUninstalledEvent) error, middleware ...Middleware) {
+	a.HandleEvent("app_uninstalled", decodedHandler(func() interface{} { return &SlackAppUninstalledEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackAppUninstalledEvent))
+	}), middleware...)
+}
+
+// OnTokensRevoked - Add handler to tokens_revoked events
+func (a *SlackApp) OnTokensRevoked(handler func(ctx *SlackContext, event *SlackTokensRevokedEvent) error, middleware ...Middleware) {
+	a.HandleEvent("tokens_revoked", decodedHandler(func() interface{} { return &SlackTokensRevokedEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackTokensRevokedEvent))
+	}), middleware...)
+}
+
+// OnLinkShared - Add handler to link_shared events
+func (a *SlackApp) OnLinkShared(handler func(ctx *SlackContext, event *SlackLinkSharedEvent) error, middleware ...Middleware) {
+	a.HandleEvent("link_shared", decodedHandler(func() interface{} { return &SlackLinkSharedEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackLinkSharedEvent))
+	}), middleware...)
+}
+
+// OnAppHomeOpenedEvent - Add handler to app_home_opened events of a tab, home or messages, an empty tab matches both
+func (a *SlackApp) OnAppHomeOpenedEvent(tab string, handler func(ctx *SlackContext, event *SlackAppHomeOpenedEvent) error, middleware ...Middleware) {
+	a.HandleAppHomeOpened(tab, decodedHandler(func() interface{} { return &SlackAppHomeOpenedEvent{} }, func(ctx *SlackContext, event interface{}) error {
+		return handler(ctx, event.(*SlackAppHomeOpenedEvent))
+	}), middleware...)
+}
+
+// decodedHandler - Handler decoding the event into the value returned by newEvent before passing it to call
+func decodedHandler(newEvent func() interface{}, call func(ctx *SlackContext, event interface{}) error) Handler {
+	return func(ctx *SlackContext) error {
+		event := newEvent()
+		if err := ctx.DecodeEvent(event); err != nil {
+			return err
+		}
+		return call(ctx, event)
+	}
+}
@@ -0,0 +1,94 @@
+package loafer
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// String - TokensCache key of the installation, the enterprise id of an org-wide install and the team id otherwise
+func (k InstallationKey) String() string {
+	if k.IsEnterpriseInstall {
+		return k.EnterpriseID
+	}
+	return k.TeamID
+}
+
+// workspace - Team id of the installation, or its enterprise id for an org-wide install without a team
+func (k InstallationKey) workspace() string {
+	if len(k.TeamID) > 0 {
+		return k.TeamID
+	}
+	return k.EnterpriseID
+}
+
+// InstallationKey - Key of the installation the app was installed with
+func (r *SlackOauth2Response) InstallationKey() InstallationKey {
+	key := InstallationKey{
+		EnterpriseID:        r.Enterprise.ID,
+		IsEnterpriseInstall: r.IsEnterpriseInstall}
+	if !r.IsEnterpriseInstall {
+		key.TeamID = r.Team.ID
+	}
+	return key
+}
+
+// installationToken - Token of the installation a request was sent for, following slack's authorization rules:
+// org-wide requests use the enterprise install, workspace requests use the workspace install then the org-wide install of their enterprise
+func (a *SlackApp) installationToken(key InstallationKey) (string, InstallationKey, error) {
+	var candidates []InstallationKey
+	if !key.IsEnterpriseInstall && len(key.TeamID) > 0 {
+		candidates = append(candidates, InstallationKey{EnterpriseID: key.EnterpriseID, TeamID: key.TeamID})
+	}
+	if len(key.EnterpriseID) > 0 {
+		candidates = append(candidates, InstallationKey{EnterpriseID: key.EnterpriseID, IsEnterpriseInstall: true})
+	}
+	for _, candidate := range candidates {
+		if token := a.getToken(candidate); len(token) > 0 {
+			return token, candidate, nil
+		}
+	}
+	return "", key, &SlackRequestError{Status: http.StatusUnauthorized, Err: fmt.Errorf("App is not installed for workspace: %s", key.workspace())}
+}
+
+// getToken - Token of an installation, from GetInstallation when the TokensCache is an InstallationCache
+func (a *SlackApp) getToken(key InstallationKey) string {
+	if cache, ok := a.opts.TokensCache.(InstallationCache); ok {
+		return cache.GetInstallation(key)
+	}
+	return a.opts.TokensCache.Get(key.String())
+}
+
+// removeToken - Remove the token of an installation, with RemoveInstallation when the TokensCache is an InstallationCache
+func (a *SlackApp) removeToken(key InstallationKey) {
+	if cache, ok := a.opts.TokensCache.(InstallationCache); ok {
+		cache.RemoveInstallation(key)
+		return
+	}
+	a.opts.TokensCache.Remove(key.String())
+}
+
+// eventInstallation - Installation an event was delivered for
+func eventInstallation(event *SlackSubscriptionEventRequest) InstallationKey {
+	if len(event.Authorizations) > 0 {
+		auth := event.Authorizations[0]
+		return InstallationKey{
+			EnterpriseID:        auth.EnterpriseID,
+			TeamID:              auth.TeamID,
+			IsEnterpriseInstall: auth.IsEnterpriseInstall}
+	}
+	return InstallationKey{
+		EnterpriseID: event.EnterpriseID,
+		TeamID:       event.TeamID}
+}
+
+// interactionInstallation - Installation an interaction was sent for, org-wide interactions may have no team
+func interactionInstallation(event *SlackInteractionEvent) InstallationKey {
+	key := InstallationKey{IsEnterpriseInstall: event.IsEnterpriseInstall}
+	if event.Enterprise != nil {
+		key.EnterpriseID = event.Enterprise.ID
+	}
+	if event.Team != nil {
+		key.TeamID = event.Team.ID
+	}
+	return key
+}
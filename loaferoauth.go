@@ -0,0 +1,106 @@
+package loafer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrInvalidOAuthState - The state of an install callback was not issued by this app, has expired or was already used
+var ErrInvalidOAuthState = errors.New("Invalid or expired OAuth state")
+
+// installStart - Handler redirecting to the slack authorize url with a new state
+func (a *SlackApp) installStart(res http.ResponseWriter, req *http.Request) {
+	state, expiry, err := a.newOAuthState()
+	if err != nil {
+		a.errorHandling(res, req, err)
+		return
+	}
+	if a.opts.StateStore != nil {
+		a.opts.StateStore.Add(state, expiry)
+	} else {
+		http.SetCookie(res, &http.Cookie{
+			Name:     OAUTHSTATECOOKIE,
+			Value:    state,
+			Path:     "/" + a.opts.Prefix + "/install",
+			Expires:  expiry,
+			HttpOnly: true,
+			Secure:   req.TLS != nil || req.Header.Get("X-Forwarded-Proto") == "https",
+			SameSite: http.SameSiteLaxMode})
+	}
+	query := url.Values{
+		"client_id":  []string{a.opts.ClientID},
+		"scope":      []string{strings.Join(a.opts.BotScopes, ",")},
+		"user_scope": []string{strings.Join(a.opts.UserScopes, ",")},
+		"state":      []string{state}}
+	if len(a.opts.RedirectURL) > 0 {
+		query.Set("redirect_uri", a.opts.RedirectURL)
+	}
+	http.Redirect(res, req, SLACKAUTHORIZEURL+"?"+query.Encode(), http.StatusFound)
+}
+
+// newOAuthState - Random state signed with the client secret, valid for OAUTHSTATELIFETIME
+func (a *SlackApp) newOAuthState() (string, time.Time, error) {
+	expiry := time.Now().Add(OAUTHSTATELIFETIME)
+	payload := make([]byte, 24)
+	if _, err := rand.Read(payload[:16]); err != nil {
+		return "", expiry, err
+	}
+	binary.BigEndian.PutUint64(payload[16:], uint64(expiry.Unix()))
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + a.signOAuthState(encoded), expiry, nil
+}
+
+// signOAuthState - Signature of an encoded state payload
+func (a *SlackApp) signOAuthState(encoded string) string {
+	mac := hmac.New(sha256.New, []byte(a.opts.ClientSecret))
+	mac.Write([]byte(encoded))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyOAuthState - Check the state of an install callback was issued by installStart for this browser and hasn't expired
+func (a *SlackApp) verifyOAuthState(req *http.Request) error {
+	state := req.URL.Query().Get("state")
+	parts := strings.SplitN(state, ".", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(a.signOAuthState(parts[0]))) {
+		return ErrInvalidOAuthState
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(payload) != 24 {
+		return ErrInvalidOAuthState
+	}
+	if time.Now().Unix() > int64(binary.BigEndian.Uint64(payload[16:])) {
+		return ErrInvalidOAuthState
+	}
+	if a.opts.StateStore != nil {
+		if !a.opts.StateStore.Consume(state) {
+			return ErrInvalidOAuthState
+		}
+		return nil
+	}
+	cookie, err := req.Cookie(OAUTHSTATECOOKIE)
+	if err != nil || !hmac.Equal([]byte(cookie.Value), []byte(state)) {
+		return ErrInvalidOAuthState
+	}
+	return nil
+}
+
+// clearOAuthState - Remove the state cookie once the callback used it
+func (a *SlackApp) clearOAuthState(res http.ResponseWriter) {
+	if a.opts.StateStore != nil {
+		return
+	}
+	http.SetCookie(res, &http.Cookie{
+		Name:     OAUTHSTATECOOKIE,
+		Path:     "/" + a.opts.Prefix + "/install",
+		MaxAge:   -1,
+		HttpOnly: true})
+}
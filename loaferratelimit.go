@@ -0,0 +1,112 @@
+package loafer
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// methodTiers - Rate limit tier of the Web API methods used by loafer
+var methodTiers = map[string]RateTier{
+	"apps.connections.open": TIER1,
+	"files.upload":          TIER2,
+	"chat.update":           TIER3,
+	"users.lookupByEmail":   TIER3,
+	"users.info":            TIER4,
+	"views.open":            TIER4,
+	"views.update":          TIER4,
+	"views.push":            TIER4,
+	"views.publish":         TIER4,
+	"chat.postMessage":      TIERPOSTMESSAGE}
+
+// InitializeRateLimiter - Return a rate limiter that queues calls per workspace and method tier
+func InitializeRateLimiter() *RateLimiter {
+	tiers := make(map[string]RateTier)
+	for method, tier := range methodTiers {
+		tiers[method] = tier
+	}
+	return &RateLimiter{
+		next:  make(map[string]time.Time),
+		tiers: tiers}
+}
+
+// SetTier - Set the tier of a Web API method, methods without a tier are treated as Tier 3
+func (l *RateLimiter) SetTier(method string, tier RateTier) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tiers[method] = tier
+}
+
+// interval - Time between two calls of a tier
+func (t RateTier) interval() time.Duration {
+	switch t {
+	case TIER1:
+		return time.Minute
+	case TIER2:
+		return time.Minute / 20
+	case TIER4:
+		return time.Minute / 100
+	case TIERPOSTMESSAGE:
+		return time.Second
+	default:
+		return time.Minute / 50
+	}
+}
+
+// reserve - Reserve the next slot of a key, returns how long to wait for it
+func (l *RateLimiter) reserve(key string, method string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tier, ok := l.tiers[method]
+	if !ok {
+		tier = TIER3
+	}
+	now := time.Now()
+	l.prune(now)
+	at := l.next[key]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[key] = at.Add(tier.interval())
+	return at.Sub(now)
+}
+
+// prune - Remove the keys whose next slot has passed, at most once per interval of the slowest tier
+// so keys of e.g. every channel posted to don't pile up
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.pruned) < TIER1.interval() {
+		return
+	}
+	l.pruned = now
+	for key, at := range l.next {
+		if at.Before(now) {
+			delete(l.next, key)
+		}
+	}
+}
+
+// delay - Push back every queued call of a key after slack asked to retry later
+func (l *RateLimiter) delay(key string, wait time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if at := time.Now().Add(wait); at.After(l.next[key]) {
+		l.next[key] = at
+	}
+}
+
+// rateLimitKey - Workspace, method and for chat.postMessage the channel a call is limited by
+func (c *Client) rateLimitKey(method string, form url.Values) string {
+	if method == "chat.postMessage" {
+		return c.token + "/" + method + "/" + form.Get("channel")
+	}
+	return c.token + "/" + method
+}
+
+// retryAfter - Time to wait before retrying a rate limited call
+func retryAfter(resp *http.Response, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Second << uint(attempt)
+}
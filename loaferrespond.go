@@ -0,0 +1,144 @@
+package loafer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoResponseURL - The request has no response_url to respond to
+	ErrNoResponseURL = errors.New("Request has no response_url")
+	// ErrResponseURLExpired - The response_url is older than slack's 30 minutes limit
+	ErrResponseURLExpired = errors.New("response_url has expired after 30 minutes")
+	// ErrResponseURLExhausted - The response_url has been used slack's 5 times limit
+	ErrResponseURLExhausted = errors.New("response_url has been used 5 times")
+)
+
+// Respond - Post a message to the response_url of the command or interaction
+func (ctx *SlackContext) Respond(message SlackResponseMessage) error {
+	if len(ctx.ResponseURL) == 0 {
+		return ErrNoResponseURL
+	}
+	httpClient := defaultHTTPClient
+	if ctx.app != nil {
+		if err := ctx.app.responseURLs.use(ctx.ResponseURL); err != nil {
+			return err
+		}
+		if ctx.app.opts.HTTPClient != nil {
+			httpClient = ctx.app.opts.HTTPClient
+		}
+	}
+	jsonMessage, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	reqCtx := ctx.Context
+	if reqCtx == nil {
+		reqCtx = context.Background()
+	}
+	r, err := http.NewRequestWithContext(reqCtx, "POST", ctx.ResponseURL, bytes.NewReader(jsonMessage))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	bodyText, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var status struct {
+		Ok *bool `json:"ok"`
+		SlackAPIError
+	}
+	if json.Unmarshal(bodyText, &status) == nil && status.Ok != nil {
+		if *status.Ok {
+			return nil
+		}
+	} else if resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	} else {
+		status.Code = strings.TrimSpace(string(bodyText))
+	}
+	apiErr := status.SlackAPIError
+	apiErr.Method = "response_url"
+	apiErr.StatusCode = resp.StatusCode
+	return &apiErr
+}
+
+// RespondInChannel - Post a message visible to everyone in the channel
+func (ctx *SlackContext) RespondInChannel(text string, blocks ISlackBlockKitUI) error {
+	return ctx.Respond(SlackResponseMessage{
+		ResponseType: "in_channel",
+		Text:         text,
+		Blocks:       blocks})
+}
+
+// RespondEphemeral - Post a message only visible to the user
+func (ctx *SlackContext) RespondEphemeral(text string, blocks ISlackBlockKitUI) error {
+	return ctx.Respond(SlackResponseMessage{
+		ResponseType: "ephemeral",
+		Text:         text,
+		Blocks:       blocks})
+}
+
+// ReplaceOriginal - Replace the message the interaction came from
+func (ctx *SlackContext) ReplaceOriginal(text string, blocks ISlackBlockKitUI) error {
+	return ctx.Respond(SlackResponseMessage{
+		Text:            text,
+		Blocks:          blocks,
+		ReplaceOriginal: true})
+}
+
+// DeleteOriginal - Delete the message the interaction came from
+func (ctx *SlackContext) DeleteOriginal() error {
+	return ctx.Respond(SlackResponseMessage{DeleteOriginal: true})
+}
+
+// newResponseURLTracker - Tracker of response urls, clock defaults to time.Now
+func newResponseURLTracker(clock func() time.Time) *responseURLTracker {
+	if clock == nil {
+		clock = time.Now
+	}
+	t := &responseURLTracker{
+		now:  clock,
+		uses: make(map[string]int)}
+	t.urls.expired = func(responseURL string) {
+		delete(t.uses, responseURL)
+	}
+	return t
+}
+
+// track - Remember when a response_url was received, a response_url received again keeps its first expiry and uses
+func (t *responseURLTracker) track(responseURL string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.urls.add(responseURL, now.Add(RESPONSEURLLIFETIME), now)
+}
+
+// use - Count a use of a response_url, fails once it's expired or used up.
+// A response_url that isn't tracked has been purged after expiring
+func (t *responseURLTracker) use(responseURL string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.urls.purge(now)
+	if expiry, ok := t.urls.expiries[responseURL]; !ok || now.After(expiry) {
+		return ErrResponseURLExpired
+	}
+	if t.uses[responseURL] >= RESPONSEURLMAXUSES {
+		return ErrResponseURLExhausted
+	}
+	t.uses[responseURL]++
+	return nil
+}
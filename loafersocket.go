@@ -0,0 +1,382 @@
+package loafer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	socketGUID       = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	socketText       = 0x1
+	socketClose      = 0x8
+	socketPing       = 0x9
+	socketPong       = 0xA
+	socketMaxBackoff = 30 * time.Second
+
+	socketPingInterval = 30 * time.Second // Pings keep the connection busy so a half-open one times out
+	socketReadTimeout  = 90 * time.Second // Max wait for a frame before reconnecting
+	socketMaxMessage   = 16 << 20         // Max size of a message, its frames included
+)
+
+// socketConn - Minimal websocket client connection used by socket mode
+type socketConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+	mu   sync.Mutex
+}
+
+// socketResponse - Captures what a handler writes so it can be sent back as the ack payload
+type socketResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+// Header - Response headers
+func (r *socketResponse) Header() http.Header {
+	return r.header
+}
+
+// Write - Write response body
+func (r *socketResponse) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// WriteHeader - Write response status code
+func (r *socketResponse) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+}
+
+// ServeSocketMode - Receive requests over a socket mode connection instead of the public routes, blocks until ctx is done
+func (a *SlackApp) ServeSocketMode(ctx context.Context) error {
+	if len(a.opts.AppToken) == 0 {
+		return errors.New("Socket mode requires an app-level token")
+	}
+	backoff := time.Second
+	for {
+		connected, err := a.runSocket(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if connected {
+			backoff = time.Second
+		}
+		if err != nil {
+			log.Println(err.Error())
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > socketMaxBackoff {
+				backoff = socketMaxBackoff
+			}
+		}
+	}
+}
+
+// runSocket - Open one socket mode connection and serve it until slack asks to reconnect
+func (a *SlackApp) runSocket(ctx context.Context) (bool, error) {
+	socketURL, err := a.openSocketURL(ctx)
+	if err != nil {
+		return false, err
+	}
+	conn, err := dialSocket(ctx, socketURL)
+	if err != nil {
+		return false, err
+	}
+	defer conn.close()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ping := time.NewTicker(socketPingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				conn.close()
+				return
+			case <-stop:
+				return
+			case <-ping.C:
+				conn.writeMessage(socketPing, nil)
+			}
+		}
+	}()
+	connected := false
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		message, err := conn.readMessage()
+		if err != nil {
+			return connected, err
+		}
+		var envelope SlackSocketEnvelope
+		if err := json.Unmarshal(message, &envelope); err != nil {
+			return connected, err
+		}
+		switch envelope.Type {
+		case "hello":
+			connected = true
+		case "disconnect":
+			return true, nil
+		default:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				a.handleEnvelope(ctx, conn, &envelope)
+			}()
+		}
+	}
+}
+
+// openSocketURL - Calls Slack apps.connections.open API for a websocket url
+func (a *SlackApp) openSocketURL(ctx context.Context) (string, error) {
+	var connection struct {
+		URL string `json:"url"`
+	}
+	err := a.Client(a.opts.AppToken).call(ctx, "apps.connections.open", url.Values{}, &connection)
+	if err != nil {
+		return "", err
+	}
+	return connection.URL, nil
+}
+
+// handleEnvelope - Dispatch an envelope to the app handlers and ack it
+func (a *SlackApp) handleEnvelope(ctx context.Context, conn *socketConn, envelope *SlackSocketEnvelope) {
+	var body []byte
+	var dispatch func(res http.ResponseWriter, req *http.Request, body []byte)
+	var route string
+	switch envelope.Type {
+	case "events_api":
+		body, route, dispatch = envelope.Payload, "events", a.handleEvent
+	case "slash_commands":
+		var fields map[string]interface{}
+		if err := json.Unmarshal(envelope.Payload, &fields); err != nil {
+			log.Println(err.Error())
+			return
+		}
+		form := url.Values{}
+		for k, v := range fields {
+			form.Set(k, fmt.Sprint(v))
+		}
+		body, route, dispatch = []byte(form.Encode()), "commands", a.handleCommand
+	case "interactive":
+		form := url.Values{"payload": []string{string(envelope.Payload)}}
+		body, route, dispatch = []byte(form.Encode()), "interactions", a.handleInteraction
+	default:
+		log.Printf("Unrecognized socket mode envelope: %s\n", envelope.Type)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("/%s/%s", a.opts.Prefix, route), bytes.NewReader(body))
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	if envelope.RetryAttempt > 0 {
+		req.Header.Set("X-Slack-Retry-Num", strconv.Itoa(envelope.RetryAttempt))
+		req.Header.Set("X-Slack-Retry-Reason", envelope.RetryReason)
+	}
+	res := &socketResponse{header: make(http.Header)}
+	dispatch(res, req, body)
+	ack := map[string]interface{}{"envelope_id": envelope.EnvelopeID}
+	if envelope.AcceptsResponsePayload && res.body.Len() > 0 {
+		if json.Valid(res.body.Bytes()) {
+			ack["payload"] = json.RawMessage(res.body.Bytes())
+		} else {
+			ack["payload"] = map[string]string{"text": res.body.String()}
+		}
+	}
+	jsonAck, err := json.Marshal(ack)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	if err := conn.writeMessage(socketText, jsonAck); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// dialSocket - Open a websocket connection to a ws:// or wss:// url
+func dialSocket(ctx context.Context, rawURL string) (*socketConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	if len(u.Port()) == 0 {
+		if u.Scheme == "wss" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "wss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req := &http.Request{
+		Method:     "GET",
+		URL:        &url.URL{Scheme: "http", Host: u.Host, Path: u.Path, RawQuery: u.RawQuery},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Upgrade":               []string{"websocket"},
+			"Connection":            []string{"Upgrade"},
+			"Sec-Websocket-Key":     []string{key},
+			"Sec-Websocket-Version": []string{"13"}},
+		Host: u.Host}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	accept := sha1.Sum([]byte(key + socketGUID))
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-Websocket-Accept") != base64.StdEncoding.EncodeToString(accept[:]) {
+		conn.Close()
+		return nil, fmt.Errorf("Socket mode handshake failed: %s", resp.Status)
+	}
+	return &socketConn{conn: conn, br: br}, nil
+}
+
+// readMessage - Read the next text message, answering pings along the way.
+// Fails when no frame arrives within socketReadTimeout or the message is larger than socketMaxMessage
+func (c *socketConn) readMessage() ([]byte, error) {
+	var message []byte
+	for {
+		if err := c.conn.SetReadDeadline(time.Now().Add(socketReadTimeout)); err != nil {
+			return nil, err
+		}
+		var head [2]byte
+		if _, err := io.ReadFull(c.br, head[:]); err != nil {
+			return nil, err
+		}
+		final := head[0]&0x80 != 0
+		opcode := head[0] & 0x0F
+		masked := head[1]&0x80 != 0
+		length := uint64(head[1] & 0x7F)
+		switch length {
+		case 126:
+			var ext [2]byte
+			if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+				return nil, err
+			}
+			length = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+				return nil, err
+			}
+			length = binary.BigEndian.Uint64(ext[:])
+		}
+		if length > uint64(socketMaxMessage-len(message)) {
+			return nil, fmt.Errorf("Socket mode message larger than %d bytes", socketMaxMessage)
+		}
+		var mask [4]byte
+		if masked {
+			if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+				return nil, err
+			}
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.br, payload); err != nil {
+			return nil, err
+		}
+		if masked {
+			for i := range payload {
+				payload[i] ^= mask[i%4]
+			}
+		}
+		switch opcode {
+		case socketPing:
+			if err := c.writeMessage(socketPong, payload); err != nil {
+				return nil, err
+			}
+		case socketPong:
+		case socketClose:
+			c.writeMessage(socketClose, nil)
+			return nil, io.EOF
+		default:
+			message = append(message, payload...)
+			if final {
+				return message, nil
+			}
+		}
+	}
+}
+
+// writeMessage - Write a single masked frame
+func (c *socketConn) writeMessage(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 0x80|126, byte(length>>8), byte(length))
+	default:
+		ext := make([]byte, 8)
+		binary.BigEndian.PutUint64(ext, uint64(length))
+		frame = append(append(frame, 0x80|127), ext...)
+	}
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// close - Close the underlying connection
+func (c *socketConn) close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,250 @@
+package loafer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	optionType      = reflect.TypeOf(SlackInputOption{})
+	stateValueType  = reflect.TypeOf(SlackStateValue{})
+	optionSliceType = reflect.TypeOf([]SlackInputOption{})
+)
+
+// Get - Value of an element, false when the view has no such element
+func (s *ViewState) Get(blockID string, actionID string) (SlackStateValue, bool) {
+	if s == nil {
+		return SlackStateValue{}, false
+	}
+	value, ok := s.Values[blockID][actionID]
+	return value, ok
+}
+
+// Text - Value of a plain text input
+func (s *ViewState) Text(blockID string, actionID string) string {
+	value, _ := s.Get(blockID, actionID)
+	return value.Value
+}
+
+// SelectedOption - Value of the option picked in a static or external select or radio buttons
+func (s *ViewState) SelectedOption(blockID string, actionID string) string {
+	value, _ := s.Get(blockID, actionID)
+	if value.SelectedOption == nil {
+		return ""
+	}
+	return value.SelectedOption.Value
+}
+
+// SelectedOptions - Values of the options picked in a multi select or checkboxes
+func (s *ViewState) SelectedOptions(blockID string, actionID string) []string {
+	value, _ := s.Get(blockID, actionID)
+	return optionValues(value.SelectedOptions)
+}
+
+// User - User picked in a users select
+func (s *ViewState) User(blockID string, actionID string) string {
+	value, _ := s.Get(blockID, actionID)
+	return value.SelectedUser
+}
+
+// Users - Users picked in a multi users select
+func (s *ViewState) Users(blockID string, actionID string) []string {
+	value, _ := s.Get(blockID, actionID)
+	return value.SelectedUsers
+}
+
+// Conversation - Conversation picked in a conversations select
+func (s *ViewState) Conversation(blockID string, actionID string) string {
+	value, _ := s.Get(blockID, actionID)
+	return value.SelectedConversation
+}
+
+// Conversations - Conversations picked in a multi conversations select
+func (s *ViewState) Conversations(blockID string, actionID string) []string {
+	value, _ := s.Get(blockID, actionID)
+	return value.SelectedConversations
+}
+
+// Channel - Channel picked in a channels select
+func (s *ViewState) Channel(blockID string, actionID string) string {
+	value, _ := s.Get(blockID, actionID)
+	return value.SelectedChannel
+}
+
+// Channels - Channels picked in a multi channels select
+func (s *ViewState) Channels(blockID string, actionID string) []string {
+	value, _ := s.Get(blockID, actionID)
+	return value.SelectedChannels
+}
+
+// Date - Date picked in a date picker, zero when none was picked
+func (s *ViewState) Date(blockID string, actionID string) time.Time {
+	value, _ := s.Get(blockID, actionID)
+	date, _ := value.date()
+	return date
+}
+
+// Time - Time picked in a time picker as HH:mm
+func (s *ViewState) Time(blockID string, actionID string) string {
+	value, _ := s.Get(blockID, actionID)
+	return value.SelectedTime
+}
+
+// Bind - Fill the fields of the struct pointed to by dst tagged with `slack:"block_id/action_id"`
+//
+// string fields get the text, picked option, user, conversation, channel, date or time of the element,
+// []string fields the picked options, users, conversations or channels, ints and floats the parsed text or picked option,
+// bool fields whether anything was picked, time.Time fields the picked date.
+// SlackStateValue, SlackInputOption and []SlackInputOption fields get the raw value.
+// Elements missing from the state leave their field untouched.
+func (s *ViewState) Bind(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("Bind requires a pointer to a struct")
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag := field.Tag.Get("slack")
+		if len(tag) == 0 || tag == "-" {
+			continue
+		}
+		ids := strings.SplitN(tag, "/", 2)
+		if len(ids) != 2 || len(field.PkgPath) > 0 {
+			return fmt.Errorf("Invalid slack tag on field %s: %s", field.Name, tag)
+		}
+		value, ok := s.Get(ids[0], ids[1])
+		if !ok {
+			continue
+		}
+		if err := bindValue(v.Field(i), value); err != nil {
+			return fmt.Errorf("Cannot bind %s to field %s: %s", tag, field.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// bindValue - Set a struct field from an element value
+func bindValue(field reflect.Value, value SlackStateValue) error {
+	switch field.Type() {
+	case stateValueType:
+		field.Set(reflect.ValueOf(value))
+		return nil
+	case timeType:
+		date, err := value.date()
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(date))
+		return nil
+	case optionType:
+		if value.SelectedOption != nil {
+			field.Set(reflect.ValueOf(*value.SelectedOption))
+		}
+		return nil
+	case optionSliceType:
+		field.Set(reflect.ValueOf(value.SelectedOptions))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value.scalar())
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		strs := value.list()
+		slice := reflect.MakeSlice(field.Type(), len(strs), len(strs))
+		for i, str := range strs {
+			slice.Index(i).SetString(str)
+		}
+		field.Set(slice)
+	case reflect.Bool:
+		field.SetBool(len(value.scalar()) > 0 || len(value.list()) > 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		scalar := value.scalar()
+		if len(scalar) == 0 {
+			return nil
+		}
+		n, err := strconv.ParseInt(scalar, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		scalar := value.scalar()
+		if len(scalar) == 0 {
+			return nil
+		}
+		n, err := strconv.ParseFloat(scalar, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// scalar - Single value of the element, whatever its type
+func (v SlackStateValue) scalar() string {
+	switch {
+	case v.SelectedOption != nil:
+		return v.SelectedOption.Value
+	case len(v.SelectedUser) > 0:
+		return v.SelectedUser
+	case len(v.SelectedConversation) > 0:
+		return v.SelectedConversation
+	case len(v.SelectedChannel) > 0:
+		return v.SelectedChannel
+	case len(v.SelectedDate) > 0:
+		return v.SelectedDate
+	case len(v.SelectedTime) > 0:
+		return v.SelectedTime
+	default:
+		return v.Value
+	}
+}
+
+// list - Values of a multi select or checkboxes element, whatever its type
+func (v SlackStateValue) list() []string {
+	switch {
+	case len(v.SelectedOptions) > 0:
+		return optionValues(v.SelectedOptions)
+	case len(v.SelectedUsers) > 0:
+		return v.SelectedUsers
+	case len(v.SelectedConversations) > 0:
+		return v.SelectedConversations
+	default:
+		return v.SelectedChannels
+	}
+}
+
+// date - Date of a date picker or date time picker, zero when none was picked
+func (v SlackStateValue) date() (time.Time, error) {
+	if v.SelectedDateTime > 0 {
+		return time.Unix(v.SelectedDateTime, 0), nil
+	}
+	if len(v.SelectedDate) == 0 {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", v.SelectedDate)
+}
+
+// optionValues - Values of options
+func optionValues(options []SlackInputOption) []string {
+	if options == nil {
+		return nil
+	}
+	values := make([]string, len(options))
+	for i, option := range options {
+		values[i] = option.Value
+	}
+	return values
+}
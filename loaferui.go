@@ -52,6 +52,14 @@ func MakeSlackModal(title string, callbackID string, blocks ISlackBlockKitUI, su
 		NotifyOnClose: notifyOnClose}
 }
 
+// MakeSlackHomeView - Make a slack App Home tab view
+func MakeSlackHomeView(callbackID string, blocks ISlackBlockKitUI) SlackHomeView {
+	return SlackHomeView{
+		Type:       "home",
+		Blocks:     blocks,
+		CallbackID: callbackID}
+}
+
 // MakeSlackActions - Make slack actions
 func MakeSlackActions(actions ISlackBlockKitUI) SlackBlockActions {
 	return SlackBlockActions{
@@ -333,6 +341,15 @@ func MakeSlackInputOption(text string, value string) SlackInputOption {
 		Value: value}
 }
 
+// MakeSlackOptionGroup - Make Slack select option group
+func MakeSlackOptionGroup(label string, options []SlackInputOption) SlackOptionGroup {
+	return SlackOptionGroup{
+		Label: &SlackBlockText{
+			Type: "plain_text",
+			Text: label},
+		Options: options}
+}
+
 // MakeSlackModalTimePickerInput - Make slack modal time picker input field
 func MakeSlackModalTimePickerInput(label string, placeholder string, initialTime string, actionID string) SlackInputElement {
 	isEmojiSupported := true
@@ -0,0 +1,53 @@
+package loafer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ErrNotViewSubmission - A response_action can only answer a view submission
+var ErrNotViewSubmission = errors.New("response_action can only answer a view_submission")
+
+// ViewErrors - Keep the view open and show an error under each input block, keyed by block id
+func (ctx *SlackContext) ViewErrors(errs map[string]string) error {
+	return ctx.respondViewAction(SlackViewResponse{
+		ResponseAction: "errors",
+		Errors:         errs})
+}
+
+// ViewUpdate - Replace the submitted view with another one
+func (ctx *SlackContext) ViewUpdate(view SlackModal) error {
+	return ctx.respondViewAction(SlackViewResponse{
+		ResponseAction: "update",
+		View:           &view})
+}
+
+// ViewPush - Push a view on top of the submitted one
+func (ctx *SlackContext) ViewPush(view SlackModal) error {
+	return ctx.respondViewAction(SlackViewResponse{
+		ResponseAction: "push",
+		View:           &view})
+}
+
+// ViewClear - Close every view of the stack
+func (ctx *SlackContext) ViewClear() error {
+	return ctx.respondViewAction(SlackViewResponse{ResponseAction: "clear"})
+}
+
+// respondViewAction - Answer a view submission with a response_action
+func (ctx *SlackContext) respondViewAction(action SlackViewResponse) error {
+	if ctx.Interaction == nil || ctx.Interaction.Type != "view_submission" {
+		return ErrNotViewSubmission
+	}
+	if ctx.acknowledged() {
+		return ErrAcknowledged
+	}
+	jsonAction, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+	Response(ctx, http.StatusOK, jsonAction, map[string]string{
+		"Content-Type": "application/json"})
+	return nil
+}
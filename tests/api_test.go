@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	loafer "github.com/arkjxu/loafer"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer xoxb-test" || req.Header.Get("User-Agent") != "loafer-test" {
+			t.Errorf("got headers %v", req.Header)
+		}
+		req.ParseForm()
+		switch req.URL.Path {
+		case "/api/chat.postMessage":
+			if req.Form.Get("channel") != "C1" || req.Form.Get("text") != "hello" {
+				t.Errorf("got form %v", req.Form)
+			}
+			res.Write([]byte(`{"ok":true}`))
+		case "/api/users.info":
+			res.Write([]byte(`{"ok":true,"user":{"id":"` + req.Form.Get("user") + `","name":"kevin"}}`))
+		default:
+			t.Errorf("unexpected call to %s", req.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := loafer.InitializeClient(&loafer.ClientOptions{
+		Token:      "xoxb-test",
+		BaseURL:    server.URL + "/api",
+		HTTPClient: server.Client(),
+		UserAgent:  "loafer-test"})
+	if err := client.PostMessage("C1", nil, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	user, err := client.FindUserByID("U1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != "U1" || user.Name != "kevin" {
+		t.Fatalf("got user %+v", user)
+	}
+}
+
+func TestSlackAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte(`{"ok":false,"error":"channel_not_found","warning":"missing_charset","response_metadata":{"messages":["[ERROR] no such channel"]}}`))
+	}))
+	defer server.Close()
+
+	client := loafer.InitializeClient(&loafer.ClientOptions{Token: "xoxb-test", BaseURL: server.URL})
+	for name, call := range map[string]func() error{
+		"chat.postMessage": func() error { return client.PostMessage("C1", nil, "hello") },
+		"files.upload":     func() error { return client.FileUpload([]string{"C1"}, "a.txt", "a", "text") },
+		"users.info": func() error {
+			_, err := client.FindUserByID("U1")
+			return err
+		}} {
+		var apiErr *loafer.SlackAPIError
+		if err := call(); !errors.As(err, &apiErr) {
+			t.Fatalf("%s: got %v, want SlackAPIError", name, err)
+		}
+		if apiErr.Method != name || apiErr.Code != "channel_not_found" || apiErr.Warning != "missing_charset" || apiErr.StatusCode != http.StatusOK {
+			t.Fatalf("%s: got %+v", name, apiErr)
+		}
+		if len(apiErr.ResponseMetadata.Messages) != 1 {
+			t.Fatalf("%s: got messages %v", name, apiErr.ResponseMetadata.Messages)
+		}
+	}
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			res.Header().Set("Retry-After", "0")
+			res.WriteHeader(http.StatusTooManyRequests)
+			res.Write([]byte(`{"ok":false,"error":"ratelimited"}`))
+			return
+		}
+		res.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	limiter := loafer.InitializeRateLimiter()
+	client := loafer.InitializeClient(&loafer.ClientOptions{Token: "xoxb-test", BaseURL: server.URL, RateLimiter: limiter})
+	started := time.Now()
+	if err := client.PostMessage("C1", nil, "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PostMessage("C2", nil, "other channel"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed >= time.Second {
+		t.Fatalf("calls to different channels waited %s", elapsed)
+	}
+	if err := client.PostMessage("C1", nil, "second"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed < time.Second {
+		t.Fatalf("second call to the same channel only waited %s", elapsed)
+	}
+	if calls != 4 {
+		t.Fatalf("got %d calls, want 4", calls)
+	}
+
+	noRetry := loafer.InitializeClient(&loafer.ClientOptions{Token: "xoxb-test", BaseURL: server.URL, MaxRetries: -1})
+	atomic.StoreInt32(&calls, 0)
+	var apiErr *loafer.SlackAPIError
+	if err := noRetry.PostMessage("C1", nil, "hello"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("got %v, want ratelimited error", err)
+	}
+}
+
+func TestClientContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := loafer.InitializeClient(&loafer.ClientOptions{Token: "xoxb-test", BaseURL: server.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.PostMessageContext(ctx, "C1", nil, "hello"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want deadline exceeded", err)
+	}
+}
+
+func TestViewsAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		var view map[string]interface{}
+		if err := json.Unmarshal([]byte(req.Form.Get("view")), &view); err != nil {
+			t.Errorf("%s: got view %q", req.URL.Path, req.Form.Get("view"))
+		}
+		switch {
+		case req.Form.Get("hash") == "stale":
+			res.Write([]byte(`{"ok":false,"error":"hash_conflict"}`))
+		case req.Form.Get("user_id") == "U2":
+			res.Write([]byte(`{"ok":false,"error":"user_not_found"}`))
+		case req.URL.Path == "/views.push" && req.Form.Get("trigger_id") == "trigger-1",
+			req.URL.Path == "/views.open" && req.Form.Get("trigger_id") == "trigger-2",
+			req.URL.Path == "/views.update" && req.Form.Get("view_id") == "V1" && len(req.Form.Get("hash")) == 0,
+			req.URL.Path == "/views.update" && req.Form.Get("external_id") == "coaching-1",
+			req.URL.Path == "/views.update" && req.Form.Get("view_id") == "V1" && req.Form.Get("hash") == "h1",
+			req.URL.Path == "/views.publish" && req.Form.Get("user_id") == "U1":
+			res.Write([]byte(`{"ok":true,"view":{"id":"V1","hash":"h2","type":"` + view["type"].(string) + `"}}`))
+		default:
+			t.Errorf("unexpected call to %s with %v", req.URL.Path, req.Form)
+		}
+	}))
+	defer server.Close()
+
+	client := loafer.InitializeClient(&loafer.ClientOptions{Token: "xoxb-test", BaseURL: server.URL})
+	modal := loafer.MakeSlackModal("Coaching", "coaching", nil, "Save", "Cancel", false)
+	modal.ExternalID = "coaching-1"
+	for name, call := range map[string]func() (*loafer.SlackInteractionView, error){
+		"push": func() (*loafer.SlackInteractionView, error) {
+			return client.PushView(modal, "trigger-1")
+		},
+		"open": func() (*loafer.SlackInteractionView, error) {
+			return client.OpenViewWithResult(modal, "trigger-2")
+		},
+		"update": func() (*loafer.SlackInteractionView, error) {
+			return client.UpdateViewWithResult(modal, "V1")
+		},
+		"external": func() (*loafer.SlackInteractionView, error) {
+			return client.UpdateViewByExternalID(modal, "coaching-1")
+		},
+		"hash": func() (*loafer.SlackInteractionView, error) {
+			return client.UpdateViewWithHash(modal, "V1", "h1")
+		},
+		"publish": func() (*loafer.SlackInteractionView, error) {
+			return client.PublishView(map[string]interface{}{"type": "home", "blocks": []interface{}{}}, "U1", "")
+		}} {
+		view, err := call()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if view.ID != "V1" || view.Hash != "h2" {
+			t.Fatalf("%s: got view %+v", name, view)
+		}
+	}
+	_, err := client.UpdateViewWithHash(modal, "V1", "stale")
+	var apiErr *loafer.SlackAPIError
+	if !errors.Is(err, loafer.ErrStaleViewHash) || !errors.As(err, &apiErr) || apiErr.Code != "hash_conflict" {
+		t.Fatalf("got %v, want ErrStaleViewHash", err)
+	}
+	if _, err := client.PublishView(modal, "U2", ""); errors.Is(err, loafer.ErrStaleViewHash) {
+		t.Fatalf("got %v, want a different error", err)
+	}
+}
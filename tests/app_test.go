@@ -0,0 +1,1095 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	loafer "github.com/arkjxu/loafer"
+)
+
+func newTestApp(prefix string) loafer.SlackApp {
+	opts := loafer.SlackAppOptions{
+		Name:          "Test Bot",
+		Prefix:        prefix,
+		TokensCache:   &TokenCache{tokens: map[string]string{"T1": "xoxb-test"}},
+		SigningSecret: "test-secret"}
+	return loafer.InitializeSlackApp(&opts)
+}
+
+// signedRequest - Build a request signed the way slack signs it
+func signedRequest(path string, body string, sentAt time.Time) *http.Request {
+	ts := strconv.FormatInt(sentAt.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte("test-secret"))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+// interactionBody - Encode an interaction payload the way slack sends it
+func interactionBody(payload string) string {
+	return url.Values{"payload": []string{payload}}.Encode()
+}
+
+func TestServeHTTP(t *testing.T) {
+	first := newTestApp("first")
+	second := newTestApp("second")
+	first.CustomRoute("ping", func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("first"))
+	})
+	second.CustomRoute("ping", func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("second"))
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/first/", &first)
+	mux.Handle("/second/", &second)
+
+	for path, want := range map[string]string{"/first/ping": "first", "/second/ping": "second"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Fatalf("%s: got %d %q, want %q", path, rec.Code, rec.Body.String(), want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	first.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown path: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	rec = httptest.NewRecorder()
+	first.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/first/commands", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned command: got %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRequestVerification(t *testing.T) {
+	opts := loafer.SlackAppOptions{
+		Prefix:        "dev",
+		TokensCache:   &TokenCache{tokens: map[string]string{"T1": "xoxb-test"}},
+		SigningSecret: "test-secret",
+		RejectReplays: true}
+	app := loafer.InitializeSlackApp(&opts)
+	app.OnCommand("/ping", func(ctx *loafer.SlackContext) {
+		loafer.Response(ctx, http.StatusOK, []byte("pong"), nil)
+	})
+	body := "team_id=T1&command=%2Fping"
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/commands", body, time.Now().Add(-10*time.Minute)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("stale request: got %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req := signedRequest("/dev/commands", body, time.Now())
+	replay := signedRequest("/dev/commands", body, time.Now())
+	replay.Header = req.Header.Clone()
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "pong" {
+		t.Fatalf("signed request: got %d %q", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, replay)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed request: got %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestOnOptions(t *testing.T) {
+	app := newTestApp("dev")
+	app.OnOptions("pick_team", func(ctx *loafer.SlackContext, query string) (*loafer.SlackOptionsResponse, error) {
+		return &loafer.SlackOptionsResponse{
+			Options: []loafer.SlackInputOption{loafer.MakeSlackInputOption("Team "+query, query)}}, nil
+	})
+	body := interactionBody(`{"type":"block_suggestion","team":{"id":"T1"},"action_id":"pick_team","block_id":"b","value":"ops"}`)
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/interactions", body, time.Now()))
+	want := `{"options":[{"text":{"type":"plain_text","text":"Team ops","emoji":true},"value":"ops"}]}`
+	if rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Fatalf("got %d %s, want %s", rec.Code, rec.Body.String(), want)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("got content type %q", ct)
+	}
+
+	app.OnOptions("pick_nothing", func(ctx *loafer.SlackContext, query string) (*loafer.SlackOptionsResponse, error) {
+		if query == "nil" {
+			return nil, nil
+		}
+		return &loafer.SlackOptionsResponse{Options: []loafer.SlackInputOption{}}, nil
+	})
+	for _, query := range []string{"nil", "empty"} {
+		body := interactionBody(`{"type":"block_suggestion","team":{"id":"T1"},"action_id":"pick_nothing","block_id":"b","value":"` + query + `"}`)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, signedRequest("/dev/interactions", body, time.Now()))
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"options":[]}` {
+			t.Fatalf("%s: got %d %s", query, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestHandlerContext(t *testing.T) {
+	app := newTestApp("dev")
+	var deadline time.Time
+	app.OnCommand("/ping", func(ctx *loafer.SlackContext) {
+		deadline, _ = ctx.Context.Deadline()
+		loafer.Response(ctx, http.StatusOK, nil, nil)
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/commands", "team_id=T1&command=%2Fping", time.Now()))
+	if left := time.Until(deadline); left <= 0 || left > 3*time.Second {
+		t.Fatalf("got deadline in %s, want within 3s", left)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	app := newTestApp("dev")
+	app.HandleCommand("/fail", func(ctx *loafer.SlackContext) error {
+		return errors.New("backend unavailable")
+	})
+	app.OnCommand("/panic", func(ctx *loafer.SlackContext) {
+		panic("boom")
+	})
+	for command, want := range map[string]int{
+		"/fail":    http.StatusInternalServerError,
+		"/panic":   http.StatusInternalServerError,
+		"/unknown": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, signedRequest("/dev/commands", "team_id=T1&command="+url.QueryEscape(command), time.Now()))
+		if rec.Code != want {
+			t.Fatalf("%s: got %d, want %d", command, rec.Code, want)
+		}
+	}
+
+	var handled error
+	app.OnError(func(res http.ResponseWriter, req *http.Request, err error) {
+		handled = err
+		res.WriteHeader(http.StatusOK)
+	})
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/commands", "team_id=T1&command=%2Fpanic", time.Now()))
+	var panicErr *loafer.PanicError
+	if !errors.As(handled, &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Fatalf("got %v, want PanicError with stack", handled)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	app := newTestApp("dev")
+	var calls []string
+	trace := func(name string) loafer.Middleware {
+		return func(next loafer.Handler) loafer.Handler {
+			return func(ctx *loafer.SlackContext) error {
+				calls = append(calls, name+":"+ctx.Workspace)
+				return next(ctx)
+			}
+		}
+	}
+	deny := func(next loafer.Handler) loafer.Handler {
+		return func(ctx *loafer.SlackContext) error {
+			return &loafer.SlackRequestError{Status: http.StatusForbidden, Err: errors.New("Not allowed")}
+		}
+	}
+	app.Use(trace("global"))
+	app.OnCommand("/ping", func(ctx *loafer.SlackContext) {
+		calls = append(calls, "handler")
+		loafer.Response(ctx, http.StatusOK, nil, nil)
+	}, trace("route"))
+	app.OnCommand("/admin", func(ctx *loafer.SlackContext) {
+		t.Fatal("denied handler ran")
+	}, deny)
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/commands", "team_id=T1&command=%2Fping", time.Now()))
+	if got := strings.Join(calls, ","); got != "global:T1,route:T1,handler" {
+		t.Fatalf("got calls %s", got)
+	}
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/commands", "team_id=T1&command=%2Fadmin", time.Now()))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestAck(t *testing.T) {
+	opts := loafer.SlackAppOptions{
+		Prefix:        "dev",
+		TokensCache:   &TokenCache{tokens: map[string]string{"T1": "xoxb-test"}},
+		SigningSecret: "test-secret",
+		Workers:       1}
+	app := loafer.InitializeSlackApp(&opts)
+	var running, maxRunning, finished int32
+	var mu sync.Mutex
+	var seen []string
+	app.OnCommand("/report", func(ctx *loafer.SlackContext) {
+		if n := atomic.AddInt32(&running, 1); n > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, n)
+		}
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		seen = append(seen, ctx.Token+" "+ctx.ResponseURL)
+		mu.Unlock()
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&finished, 1)
+	}, app.Ack(map[string]string{"text": "Working on it"}))
+
+	body := "team_id=T1&command=%2Freport&response_url=" + url.QueryEscape("https://hooks.slack.com/commands/1")
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, signedRequest("/dev/commands", body, time.Now()))
+		if rec.Code != http.StatusOK || rec.Body.String() != `{"text":"Working on it"}` {
+			t.Fatalf("got %d %s", rec.Code, rec.Body.String())
+		}
+	}
+	if atomic.LoadInt32(&finished) != 0 {
+		t.Fatal("handler ran before the ack was sent")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	app.Close(ctx)
+	if finished != 2 || maxRunning != 1 {
+		t.Fatalf("got %d finished with %d concurrent, want 2 with 1", finished, maxRunning)
+	}
+	if seen[0] != "xoxb-test https://hooks.slack.com/commands/1" {
+		t.Fatalf("got %q", seen[0])
+	}
+
+	opts.QueueSize = 1
+	app = loafer.InitializeSlackApp(&opts)
+	release := make(chan struct{})
+	app.OnCommand("/report", func(ctx *loafer.SlackContext) {
+		<-release
+	}, app.Ack(nil))
+	var codes []int
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, signedRequest("/dev/commands", body, time.Now()))
+		codes = append(codes, rec.Code)
+	}
+	close(release)
+	app.Close(ctx)
+	if fmt.Sprint(codes) != "[200 200 503]" {
+		t.Fatalf("got %v, want the third request rejected once a handler runs and one waits", codes)
+	}
+}
+
+type tenantKey struct{}
+
+func TestAckResponses(t *testing.T) {
+	posted := make(chan string, 1)
+	hooks := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var message map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&message)
+		blocks, _ := json.Marshal(message["blocks"])
+		posted <- fmt.Sprint(message["response_type"], " ", strings.Contains(string(blocks), "/coaching"))
+		res.Write([]byte("ok"))
+	}))
+	defer hooks.Close()
+
+	app := newTestApp("dev")
+	app.Use(func(next loafer.Handler) loafer.Handler {
+		return func(ctx *loafer.SlackContext) error {
+			ctx.Context = context.WithValue(ctx.Context, tenantKey{}, "acme")
+			return next(ctx)
+		}
+	})
+	router := loafer.InitializeCommandRouter("/coaching")
+	router.Add("book", "Book a session", nil, func(ctx *loafer.SlackContext, args *loafer.CommandArgs) error {
+		return nil
+	})
+	app.HandleCommand("/coaching", router.Handle, app.Ack(nil))
+	viewErr := make(chan error, 1)
+	var tenant interface{}
+	app.HandleViewSubmission("book", func(ctx *loafer.SlackContext) error {
+		tenant = ctx.Context.Value(tenantKey{})
+		viewErr <- ctx.ViewErrors(map[string]string{"when": "Pick a date"})
+		return nil
+	}, app.Ack(nil))
+
+	body := "team_id=T1&command=%2Fcoaching&text=help&response_url=" + url.QueryEscape(hooks.URL+"/commands/1")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/commands", body, time.Now()))
+	select {
+	case got := <-posted:
+		if rec.Code != http.StatusOK || got != "ephemeral true" {
+			t.Fatalf("got %d, posted %s", rec.Code, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("help was not sent to the response_url")
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), signedRequest("/dev/interactions", interactionBody(`{"type":"view_submission","team":{"id":"T1"},"view":{"callback_id":"book"}}`), time.Now()))
+	if err := <-viewErr; !errors.Is(err, loafer.ErrAcknowledged) || tenant != "acme" {
+		t.Fatalf("got %v with tenant %v", err, tenant)
+	}
+}
+
+func TestRespond(t *testing.T) {
+	var posted []string
+	hooks := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var message map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&message)
+		if message["text"] == "broken" {
+			res.WriteHeader(http.StatusNotFound)
+			res.Write([]byte("invalid_blocks"))
+			return
+		}
+		posted = append(posted, fmt.Sprint(message["response_type"], " ", message["text"]))
+		res.Write([]byte("ok"))
+	}))
+	defer hooks.Close()
+
+	app := newTestApp("dev")
+	var errs []error
+	app.HandleCommand("/notify", func(ctx *loafer.SlackContext) error {
+		errs = append(errs, ctx.RespondEphemeral("only you", nil))
+		errs = append(errs, ctx.RespondInChannel("everyone", []loafer.ISlackBlockKitUI{loafer.MakeSlackTextSection("*everyone*")}))
+		errs = append(errs, ctx.RespondEphemeral("broken", nil))
+		for i := 0; i < 3; i++ {
+			errs = append(errs, ctx.RespondEphemeral("again", nil))
+		}
+		return nil
+	})
+	body := "team_id=T1&command=%2Fnotify&response_url=" + url.QueryEscape(hooks.URL+"/commands/1")
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/commands", body, time.Now()))
+
+	if got := strings.Join(posted, ","); got != "ephemeral only you,in_channel everyone,ephemeral again,ephemeral again" {
+		t.Fatalf("got posted %s", got)
+	}
+	if errs[0] != nil || errs[1] != nil {
+		t.Fatalf("got errors %v", errs)
+	}
+	var apiErr *loafer.SlackAPIError
+	if !errors.As(errs[2], &apiErr) || apiErr.Code != "invalid_blocks" || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("got %v, want invalid_blocks", errs[2])
+	}
+	if !errors.Is(errs[5], loafer.ErrResponseURLExhausted) {
+		t.Fatalf("got %v, want ErrResponseURLExhausted", errs[5])
+	}
+}
+
+func TestResponseURLExpiry(t *testing.T) {
+	posts := 0
+	hooks := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		posts++
+		res.Write([]byte("ok"))
+	}))
+	defer hooks.Close()
+
+	now := time.Now()
+	app := loafer.InitializeSlackApp(&loafer.SlackAppOptions{
+		Prefix:        "dev",
+		TokensCache:   &TokenCache{tokens: map[string]string{"T1": "xoxb-test"}},
+		SigningSecret: "test-secret",
+		Clock: func() time.Time {
+			return now
+		}})
+	var contexts []*loafer.SlackContext
+	app.OnCommand("/notify", func(ctx *loafer.SlackContext) {
+		later := *ctx
+		later.Context = context.Background()
+		contexts = append(contexts, &later)
+	})
+	command := func(id string) {
+		body := "team_id=T1&command=%2Fnotify&response_url=" + url.QueryEscape(hooks.URL+"/commands/"+id)
+		app.ServeHTTP(httptest.NewRecorder(), signedRequest("/dev/commands", body, time.Now()))
+	}
+	command("1")
+	if err := contexts[0].RespondEphemeral("on time", nil); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(31 * time.Minute)
+	if err := contexts[0].RespondEphemeral("late", nil); !errors.Is(err, loafer.ErrResponseURLExpired) {
+		t.Fatalf("got %v, want ErrResponseURLExpired", err)
+	}
+	command("2")
+	if err := contexts[0].RespondEphemeral("late after purge", nil); !errors.Is(err, loafer.ErrResponseURLExpired) {
+		t.Fatalf("got %v, want ErrResponseURLExpired once purged", err)
+	}
+	if err := contexts[1].RespondEphemeral("on time", nil); err != nil || posts != 2 {
+		t.Fatalf("got %v after %d posts", err, posts)
+	}
+}
+
+func TestSlashCommand(t *testing.T) {
+	app := newTestApp("dev")
+	var command loafer.SlashCommand
+	app.OnCommand("/coaching", func(ctx *loafer.SlackContext) {
+		command = *ctx.Command
+	})
+	form := url.Values{
+		"team_id":               {"T1"},
+		"enterprise_id":         {"E1"},
+		"is_enterprise_install": {"false"},
+		"channel_id":            {"C1"},
+		"user_id":               {"U1"},
+		"command":               {"/coaching"},
+		"text":                  {"add <@U2|kevin> 30m"},
+		"api_app_id":            {"A1"},
+		"trigger_id":            {"13345224609.738474920.8088930838d88f008e0"},
+		"response_url":          {"https://hooks.slack.com/commands/1"}}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/commands", form.Encode(), time.Now()))
+	want := loafer.SlashCommand{
+		Command:      "/coaching",
+		Text:         "add <@U2|kevin> 30m",
+		TeamID:       "T1",
+		EnterpriseID: "E1",
+		ChannelID:    "C1",
+		UserID:       "U1",
+		APIAppID:     "A1",
+		TriggerID:    "13345224609.738474920.8088930838d88f008e0",
+		ResponseURL:  "https://hooks.slack.com/commands/1"}
+	if command != want {
+		t.Fatalf("got %+v, want %+v", command, want)
+	}
+}
+
+func TestCommandRouter(t *testing.T) {
+	if words, err := loafer.SplitCommandText(`add "weekly sync" “team notes” it\'s`); err != nil || strings.Join(words, "|") != "add|weekly sync|team notes|it's" {
+		t.Fatalf("got %q, %v", words, err)
+	}
+	if _, err := loafer.SplitCommandText(`add "weekly`); err == nil {
+		t.Fatal("unterminated quote was accepted")
+	}
+
+	var got *loafer.CommandArgs
+	router := loafer.InitializeCommandRouter("/coaching")
+	router.Add("add", "Schedule a session", []loafer.CommandArg{
+		{Name: "user", Type: loafer.ARGUSER},
+		{Name: "length", Type: loafer.ARGDURATION},
+		{Name: "kind", Type: loafer.ARGENUM, Values: []string{"intro", "review"}},
+		{Name: "count", Type: loafer.ARGINT, Optional: true}},
+		func(ctx *loafer.SlackContext, args *loafer.CommandArgs) error {
+			got = args
+			return nil
+		})
+	app := newTestApp("dev")
+	app.HandleCommand("/coaching", router.Handle)
+	run := func(text string) string {
+		form := url.Values{"team_id": {"T1"}, "command": {"/coaching"}, "text": {text}}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, signedRequest("/dev/commands", form.Encode(), time.Now()))
+		return strings.NewReplacer(`\u003c`, "<", `\u003e`, ">").Replace(rec.Body.String())
+	}
+
+	run("add <@U2|kevin> 1h30m REVIEW")
+	if got == nil || got.User("user").ID != "U2" || got.User("user").Name != "kevin" || got.Duration("length") != 90*time.Minute || got.String("kind") != "review" || got.Has("count") {
+		t.Fatalf("got args %+v", got)
+	}
+	router.Add("post", "Post a note", []loafer.CommandArg{
+		{Name: "channel", Type: loafer.ARGCHANNEL},
+		{Name: "note", Type: loafer.ARGSTRING},
+		{Name: "kind", Type: loafer.ARGENUM, Values: []string{"q&a", "retro"}}},
+		func(ctx *loafer.SlackContext, args *loafer.CommandArgs) error {
+			got = args
+			return nil
+		})
+	run(`post <#G12345|private> "Q&amp;A &lt;draft&gt;" Q&amp;A`)
+	if got.Channel("channel").ID != "G12345" || got.String("note") != "Q&A <draft>" || got.String("kind") != "q&a" {
+		t.Fatalf("got args %+v", got)
+	}
+	for text, want := range map[string]string{
+		"":                          "Schedule a session",
+		"help":                      "/coaching add <user> <length> <intro|review> [count]",
+		"ad":                        "Did you mean `/coaching add`?",
+		"add kevin 30m intro":       "is not a user mention",
+		"add <@U2> 30m intro x":     "Invalid argument <count>",
+		"add <@U2> 30m":             "Missing argument <kind>",
+		"add <@U2> 30m intro 1 2 3": "Too many arguments"} {
+		if body := run(text); !strings.Contains(body, `"response_type":"ephemeral"`) || !strings.Contains(body, want) {
+			t.Fatalf("%q: got %s, want %s", text, body, want)
+		}
+	}
+}
+
+func TestInteractionPayload(t *testing.T) {
+	app := newTestApp("dev")
+	var ctx *loafer.SlackContext
+	app.OnAction("pick", func(c *loafer.SlackContext) {
+		ctx = c
+	})
+	payload := `{
+		"type": "block_actions",
+		"team": {"id": "T1"},
+		"user": {"id": "U1", "username": "kevin"},
+		"trigger_id": "trigger-1",
+		"container": {"type": "message", "message_ts": "1.2", "channel_id": "C1", "is_ephemeral": false},
+		"channel": {"id": "C1", "name": "general"},
+		"message": {"type": "message", "ts": "1.2", "text": "pick one"},
+		"state": {"values": {"b1": {"pick": {"type": "static_select", "selected_option": {"text": {"type": "plain_text", "text": "A"}, "value": "a"}}}}},
+		"actions": [{"action_id": "pick", "block_id": "b1", "type": "static_select", "selected_option": {"value": "a"}}]
+	}`
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/interactions", interactionBody(payload), time.Now()))
+	if ctx == nil {
+		t.Fatalf("handler was not called, got %d %s", rec.Code, rec.Body.String())
+	}
+	if action := ctx.Action(); action.ActionID != "pick" || action.BlockID != "b1" || action.SelectedOption.Value != "a" {
+		t.Fatalf("got action %+v", action)
+	}
+	if ctx.User().ID != "U1" || ctx.Channel().Name != "general" || ctx.Message().Text != "pick one" || ctx.TriggerID() != "trigger-1" || ctx.View() != nil {
+		t.Fatalf("got interaction %+v", ctx.Interaction)
+	}
+	if ctx.Interaction.Container.MessageTS != "1.2" {
+		t.Fatalf("got container %+v", ctx.Interaction.Container)
+	}
+	if value := ctx.Interaction.State.Values["b1"]["pick"]; value.SelectedOption.Text.Text != "A" {
+		t.Fatalf("got state %+v", ctx.Interaction.State)
+	}
+}
+
+func TestViewStateBind(t *testing.T) {
+	var state loafer.ViewState
+	err := json.Unmarshal([]byte(`{"values": {
+		"title": {"title": {"type": "plain_text_input", "value": "Weekly sync"}},
+		"sessions": {"sessions": {"type": "plain_text_input", "value": "4"}},
+		"kind": {"kind": {"type": "static_select", "selected_option": {"value": "review"}}},
+		"length": {"length": {"type": "static_select", "selected_option": {"value": "45"}}},
+		"rating": {"rating": {"type": "radio_buttons", "selected_option": {"value": "4.5"}}},
+		"topics": {"topics": {"type": "checkboxes", "selected_options": [{"value": "career"}, {"value": "growth"}]}},
+		"coach": {"coach": {"type": "users_select", "selected_user": "U1"}},
+		"team": {"team": {"type": "multi_users_select", "selected_users": ["U2", "U3"]}},
+		"channels": {"channels": {"type": "multi_conversations_select", "selected_conversations": ["C1"]}},
+		"day": {"day": {"type": "datepicker", "selected_date": "2021-03-04"}},
+		"at": {"at": {"type": "timepicker", "selected_time": "09:30"}},
+		"notify": {"notify": {"type": "checkboxes", "selected_options": []}}
+	}}`), &state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var form struct {
+		Title    string    `slack:"title/title"`
+		Sessions int       `slack:"sessions/sessions"`
+		Kind     string    `slack:"kind/kind"`
+		Length   int       `slack:"length/length"`
+		Rating   float64   `slack:"rating/rating"`
+		Topics   []string  `slack:"topics/topics"`
+		Coach    string    `slack:"coach/coach"`
+		Team     []string  `slack:"team/team"`
+		Channels []string  `slack:"channels/channels"`
+		Day      time.Time `slack:"day/day"`
+		At       string    `slack:"at/at"`
+		Notify   bool      `slack:"notify/notify"`
+		Missing  string    `slack:"missing/missing"`
+		Ignored  string
+	}
+	form.Missing = "kept"
+	if err := state.Bind(&form); err != nil {
+		t.Fatal(err)
+	}
+	if form.Title != "Weekly sync" || form.Sessions != 4 || form.Kind != "review" || form.Length != 45 || form.Rating != 4.5 || strings.Join(form.Topics, ",") != "career,growth" ||
+		form.Coach != "U1" || strings.Join(form.Team, ",") != "U2,U3" || strings.Join(form.Channels, ",") != "C1" ||
+		form.Day.Format("2006-01-02") != "2021-03-04" || form.At != "09:30" || form.Notify || form.Missing != "kept" {
+		t.Fatalf("got %+v", form)
+	}
+	if state.SelectedOption("kind", "kind") != "review" || state.User("coach", "coach") != "U1" || state.Date("day", "day").Day() != 4 || state.Text("missing", "missing") != "" {
+		t.Fatal("typed getters returned wrong values")
+	}
+
+	var bad struct {
+		Sessions int `slack:"title/title"`
+	}
+	if err := state.Bind(&bad); err == nil {
+		t.Fatal("binding text to an int field did not fail")
+	}
+}
+
+func TestViewResponseAction(t *testing.T) {
+	app := newTestApp("dev")
+	app.HandleViewSubmission("coaching_form", func(ctx *loafer.SlackContext) error {
+		if len(ctx.View().State.Text("topic", "topic")) < 3 {
+			return ctx.ViewErrors(map[string]string{"topic": "Topic is too short"})
+		}
+		return ctx.ViewPush(loafer.MakeSlackModal("Confirm", "confirm", nil, "Ok", "Back", false))
+	})
+	app.HandleAction("pick", func(ctx *loafer.SlackContext) error {
+		if err := ctx.ViewClear(); err != loafer.ErrNotViewSubmission {
+			t.Errorf("got %v, want ErrNotViewSubmission", err)
+		}
+		return nil
+	})
+	submit := func(topic string) map[string]interface{} {
+		payload := `{"type": "view_submission", "team": {"id": "T1"}, "view": {"callback_id": "coaching_form",
+			"state": {"values": {"topic": {"topic": {"type": "plain_text_input", "value": "` + topic + `"}}}}}}`
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, signedRequest("/dev/interactions", interactionBody(payload), time.Now()))
+		var action map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &action); err != nil {
+			t.Fatalf("got %d %s", rec.Code, rec.Body.String())
+		}
+		return action
+	}
+	if action := submit("ab"); action["response_action"] != "errors" || fmt.Sprint(action["errors"]) != "map[topic:Topic is too short]" {
+		t.Fatalf("got %v", action)
+	}
+	if action := submit("career"); action["response_action"] != "push" || action["view"].(map[string]interface{})["callback_id"] != "confirm" {
+		t.Fatalf("got %v", action)
+	}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/interactions", interactionBody(`{"type": "block_actions", "team": {"id": "T1"}, "actions": [{"action_id": "pick"}]}`), time.Now()))
+}
+
+func TestAppHome(t *testing.T) {
+	var published url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		published = req.Form
+		res.Write([]byte(`{"ok":true,"view":{"id":"V1","hash":"h1"}}`))
+	}))
+	defer server.Close()
+
+	app := loafer.InitializeSlackApp(&loafer.SlackAppOptions{
+		Prefix:        "dev",
+		TokensCache:   &TokenCache{tokens: map[string]string{"T1": "xoxb-test"}},
+		SigningSecret: "test-secret",
+		APIBaseURL:    server.URL})
+	var messagesOpened bool
+	app.HandleAppHomeOpened("home", func(ctx *loafer.SlackContext) error {
+		_, err := ctx.PublishHome(loafer.MakeSlackHomeView("dashboard", []loafer.ISlackBlockKitUI{
+			loafer.MakeSlackTextSection("Sessions this week: 3")}))
+		return err
+	})
+	app.OnAppHomeOpenedEvent("messages", func(ctx *loafer.SlackContext, event *loafer.SlackAppHomeOpenedEvent) error {
+		messagesOpened = event.Tab == "messages" && event.Channel == "D1"
+		return nil
+	})
+	event := func(tab string) *http.Request {
+		return signedRequest("/dev/events", `{"type":"event_callback","team_id":"T1","event":{"type":"app_home_opened","user":"U1","channel":"D1","tab":"`+tab+`","event_ts":"1.2"}}`, time.Now())
+	}
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, event("home"))
+	if rec.Code != http.StatusOK || published.Get("user_id") != "U1" || !strings.Contains(published.Get("view"), `"type":"home"`) || !strings.Contains(published.Get("view"), "Sessions this week") {
+		t.Fatalf("got %d, published %v", rec.Code, published)
+	}
+	app.ServeHTTP(httptest.NewRecorder(), event("messages"))
+	if !messagesOpened {
+		t.Fatal("messages tab handler was not called")
+	}
+}
+
+func TestTypedEvents(t *testing.T) {
+	app := newTestApp("dev")
+	got := map[string]string{}
+	app.OnMessage("", func(ctx *loafer.SlackContext, event *loafer.SlackMessageEvent) error {
+		got["message"] = event.User + " " + event.Text + " " + event.ThreadTS
+		return nil
+	})
+	app.OnMessage("message_changed", func(ctx *loafer.SlackContext, event *loafer.SlackMessageEvent) error {
+		got["message_changed"] = event.PreviousMessage.Text + " -> " + event.Message.Text
+		return nil
+	})
+	app.OnReactionAdded(func(ctx *loafer.SlackContext, event *loafer.SlackReactionEvent) error {
+		got["reaction_added"] = event.Reaction + " " + event.Item.Channel + " " + event.Item.TS
+		return nil
+	})
+	app.OnTeamJoin(func(ctx *loafer.SlackContext, event *loafer.SlackTeamJoinEvent) error {
+		got["team_join"] = event.User.ID + " " + event.User.Name
+		return nil
+	})
+	app.OnLinkShared(func(ctx *loafer.SlackContext, event *loafer.SlackLinkSharedEvent) error {
+		got["link_shared"] = event.Links[0].URL
+		return nil
+	})
+	app.HandleEvent("pin_added", func(ctx *loafer.SlackContext) error {
+		var event map[string]interface{}
+		err := ctx.DecodeEvent(&event)
+		got["pin_added"] = fmt.Sprint(event["channel_id"], " ", ctx.Event.Event.Type)
+		return err
+	})
+	for _, event := range []string{
+		`{"type":"message","channel":"C1","user":"U1","text":"hello","ts":"1.2","thread_ts":"1.1"}`,
+		`{"type":"message","subtype":"message_changed","channel":"C1","message":{"text":"new"},"previous_message":{"text":"old"}}`,
+		`{"type":"reaction_added","user":"U1","reaction":"tada","item":{"type":"message","channel":"C1","ts":"1.2"}}`,
+		`{"type":"team_join","user":{"id":"U2","name":"kevin"}}`,
+		`{"type":"link_shared","channel":"C1","links":[{"domain":"example.com","url":"https://example.com/a"}]}`,
+		`{"type":"pin_added","channel_id":"C1"}`} {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, signedRequest("/dev/events", `{"type":"event_callback","team_id":"T1","event":`+event+`}`, time.Now()))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got %d %s", event, rec.Code, rec.Body.String())
+		}
+	}
+	want := map[string]string{
+		"message":         "U1 hello 1.1",
+		"message_changed": "old -> new",
+		"reaction_added":  "tada C1 1.2",
+		"team_join":       "U2 kevin",
+		"link_shared":     "https://example.com/a",
+		"pin_added":       "C1 pin_added"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestEventRetries(t *testing.T) {
+	opts := loafer.SlackAppOptions{
+		Prefix:        "dev",
+		TokensCache:   &TokenCache{tokens: map[string]string{"T1": "xoxb-test"}},
+		SigningSecret: "test-secret",
+		NoRetry:       true}
+	app := loafer.InitializeSlackApp(&opts)
+	var calls int
+	var retryNum int
+	var retryReason string
+	app.OnEvent("app_mention", func(ctx *loafer.SlackContext) {
+		calls++
+		retryNum, retryReason = ctx.RetryNum, ctx.RetryReason
+	})
+	deliver := func(eventID string, retry int) *httptest.ResponseRecorder {
+		req := signedRequest("/dev/events", `{"type":"event_callback","team_id":"T1","event_id":"`+eventID+`","event":{"type":"app_mention"}}`, time.Now())
+		if retry > 0 {
+			req.Header.Set("X-Slack-Retry-Num", strconv.Itoa(retry))
+			req.Header.Set("X-Slack-Retry-Reason", "http_timeout")
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := deliver("Ev1", 0); rec.Code != http.StatusOK || rec.Header().Get("X-Slack-No-Retry") != "1" {
+		t.Fatalf("got %d, headers %v", rec.Code, rec.Header())
+	}
+	if rec := deliver("Ev1", 1); rec.Code != http.StatusOK || calls != 1 {
+		t.Fatalf("retry got %d and ran the handler %d times", rec.Code, calls)
+	}
+	deliver("Ev2", 2)
+	if calls != 2 || retryNum != 2 || retryReason != "http_timeout" {
+		t.Fatalf("got %d calls, retry %d %q", calls, retryNum, retryReason)
+	}
+
+	failures := 0
+	app.HandleEvent("reaction_added", func(ctx *loafer.SlackContext) error {
+		failures++
+		if failures == 1 {
+			return errors.New("Database is down")
+		}
+		if failures == 2 {
+			panic("Database is still down")
+		}
+		return nil
+	})
+	var codes []int
+	for retry := 0; retry < 4; retry++ {
+		req := signedRequest("/dev/events", `{"type":"event_callback","team_id":"T1","event_id":"Ev3","event":{"type":"reaction_added"}}`, time.Now())
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	if fmt.Sprint(codes) != "[500 500 200 200]" || failures != 3 {
+		t.Fatalf("got %v with %d runs, want failed deliveries retried until one succeeds", codes, failures)
+	}
+}
+
+func TestURLVerification(t *testing.T) {
+	app := newTestApp("dev")
+	var verified string
+	app.OnURLVerification(func(req *http.Request, challenge string) {
+		verified = challenge
+	})
+	body := `{"token":"legacy","challenge":"3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P","type":"url_verification"}`
+
+	unsigned := httptest.NewRequest(http.MethodPost, "/dev/events", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, unsigned)
+	if rec.Code != http.StatusUnauthorized || strings.Contains(rec.Body.String(), "challenge") || len(verified) > 0 {
+		t.Fatalf("unsigned challenge got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/events", body, time.Now()))
+	if rec.Code != http.StatusOK || rec.Body.String() != "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P" || rec.Header().Get("Content-Type") != "text/plain" {
+		t.Fatalf("got %d %q %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+	if verified != "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P" {
+		t.Fatalf("hook got %q", verified)
+	}
+}
+
+func TestAppUninstall(t *testing.T) {
+	tokens := &TokenCache{tokens: map[string]string{"T1": "xoxb-1", "T2": "xoxb-2", "T3": "xoxb-3"}}
+	app := loafer.InitializeSlackApp(&loafer.SlackAppOptions{
+		Prefix:        "dev",
+		TokensCache:   tokens,
+		SigningSecret: "test-secret"})
+	var uninstalled []string
+	app.OnAppUninstall(func(ctx *loafer.SlackContext) error {
+		uninstalled = append(uninstalled, ctx.Workspace)
+		return nil
+	})
+	var revokedUsers []string
+	app.OnUserTokensRevoked(func(ctx *loafer.SlackContext, userIDs []string) error {
+		revokedUsers = append(revokedUsers, ctx.Workspace+":"+strings.Join(userIDs, "+"))
+		return nil
+	})
+	deliver := func(team string, event string) int {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, signedRequest("/dev/events", `{"type":"event_callback","team_id":"`+team+`","event":`+event+`}`, time.Now()))
+		return rec.Code
+	}
+	if code := deliver("T1", `{"type":"app_uninstalled"}`); code != http.StatusOK {
+		t.Fatalf("got %d", code)
+	}
+	deliver("T2", `{"type":"tokens_revoked","tokens":{"oauth":["U1"]}}`)
+	deliver("T3", `{"type":"tokens_revoked","tokens":{"oauth":["U1","U2"],"bot":["B1"]}}`)
+	if len(tokens.tokens) != 1 || tokens.Get("T2") != "xoxb-2" {
+		t.Fatalf("got tokens %v", tokens.tokens)
+	}
+	if strings.Join(uninstalled, ",") != "T1,T3" {
+		t.Fatalf("got uninstalls %v", uninstalled)
+	}
+	if strings.Join(revokedUsers, ",") != "T2:U1,T3:U1+U2" {
+		t.Fatalf("got revoked users %v", revokedUsers)
+	}
+
+	tokens.Set("T4", "xoxb-4")
+	var handled []string
+	app.OnAppUninstalled(func(ctx *loafer.SlackContext, event *loafer.SlackAppUninstalledEvent) error {
+		handled = append(handled, "app_uninstalled")
+		return nil
+	})
+	app.OnTokensRevoked(func(ctx *loafer.SlackContext, event *loafer.SlackTokensRevokedEvent) error {
+		handled = append(handled, "tokens_revoked")
+		return nil
+	})
+	uninstalled = nil
+	for _, event := range []string{`{"type":"app_uninstalled"}`, `{"type":"tokens_revoked","tokens":{"bot":["B4"]}}`} {
+		if code := deliver("T4", event); code != http.StatusOK {
+			t.Fatalf("%s got %d", event, code)
+		}
+	}
+	if strings.Join(handled, ",") != "app_uninstalled,tokens_revoked" || strings.Join(uninstalled, ",") != "T4" || len(tokens.tokens) != 1 {
+		t.Fatalf("got handled %v, uninstalls %v, tokens %v", handled, uninstalled, tokens.tokens)
+	}
+
+	tokens.Set("T5", "xoxb-5")
+	calls := 0
+	started, release := make(chan struct{}), make(chan struct{})
+	app.OnAppUninstall(func(ctx *loafer.SlackContext) error {
+		calls++
+		if calls == 1 {
+			return errors.New("Database is down")
+		}
+		close(started)
+		<-release
+		return nil
+	})
+	uninstallEvent := `{"type":"event_callback","team_id":"T5","event_id":"Ev5","event":{"type":"app_uninstalled"}}`
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, signedRequest("/dev/events", uninstallEvent, time.Now()))
+	if rec.Code != http.StatusInternalServerError || tokens.Get("T5") != "xoxb-5" {
+		t.Fatalf("failed callback got %d, token %q", rec.Code, tokens.Get("T5"))
+	}
+	retried := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		req := signedRequest("/dev/events", uninstallEvent, time.Now())
+		req.Header.Set("X-Slack-Retry-Num", "1")
+		app.ServeHTTP(rec, req)
+		retried <- rec.Code
+	}()
+	<-started
+	if code := deliver("T5", `{"type":"tokens_revoked","tokens":{"bot":["B5"]}}`); code != http.StatusOK {
+		t.Fatalf("concurrent tokens_revoked got %d", code)
+	}
+	close(release)
+	if code := <-retried; code != http.StatusOK || calls != 2 || len(tokens.Get("T5")) > 0 {
+		t.Fatalf("retry got %d after %d callbacks, token %q", code, calls, tokens.Get("T5"))
+	}
+}
+
+type stateStore struct {
+	states map[string]time.Time
+}
+
+func (s *stateStore) Add(state string, expiry time.Time) {
+	s.states[state] = expiry
+}
+
+func (s *stateStore) Consume(state string) bool {
+	expiry, ok := s.states[state]
+	delete(s.states, state)
+	return ok && time.Now().Before(expiry)
+}
+
+func TestInstallState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		if req.Form.Get("code") == "expired" {
+			res.Write([]byte(`{"ok":false,"error":"invalid_code"}`))
+			return
+		}
+		if req.URL.Path != "/oauth.v2.access" || req.Form.Get("code") != "code-1" || req.Form.Get("client_secret") != "client-secret" {
+			t.Errorf("got %s %v", req.URL.Path, req.Form)
+		}
+		res.Write([]byte(`{"ok":true,"access_token":"xoxb-new","team":{"id":"T9"}}`))
+	}))
+	defer server.Close()
+	newApp := func(store loafer.StateStore) (loafer.SlackApp, *int) {
+		app := loafer.InitializeSlackApp(&loafer.SlackAppOptions{
+			Name:         "Test Bot",
+			Prefix:       "dev",
+			TokensCache:  &TokenCache{tokens: map[string]string{}},
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			BotScopes:    []string{"commands", "chat:write"},
+			UserScopes:   []string{"identity.basic"},
+			StateStore:   store,
+			APIBaseURL:   server.URL})
+		installs := 0
+		app.OnAppInstall(func(installRes *loafer.SlackOauth2Response, res http.ResponseWriter, req *http.Request) bool {
+			installs++
+			return false
+		})
+		return app, &installs
+	}
+	start := func(app loafer.SlackApp) (string, *http.Cookie) {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dev/install/start", nil))
+		location, err := url.Parse(rec.Header().Get("Location"))
+		if rec.Code != http.StatusFound || err != nil {
+			t.Fatalf("got %d %v", rec.Code, rec.Header())
+		}
+		query := location.Query()
+		if location.Host != "slack.com" || query.Get("client_id") != "client-id" || query.Get("scope") != "commands,chat:write" || query.Get("user_scope") != "identity.basic" {
+			t.Fatalf("got authorize url %s", location)
+		}
+		var cookie *http.Cookie
+		if cookies := rec.Result().Cookies(); len(cookies) > 0 {
+			cookie = cookies[0]
+		}
+		return query.Get("state"), cookie
+	}
+	callback := func(app loafer.SlackApp, query string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/dev/install?"+query, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec
+	}
+
+	app, installs := newApp(nil)
+	state, cookie := start(app)
+	if cookie == nil || cookie.Value != state || !cookie.HttpOnly {
+		t.Fatalf("got cookie %+v for state %s", cookie, state)
+	}
+	if rec := callback(app, "code=code-1&state="+url.QueryEscape(state), nil); rec.Code != http.StatusForbidden || *installs != 0 {
+		t.Fatalf("callback without cookie got %d", rec.Code)
+	}
+	if rec := callback(app, "code=code-1&state=forged", &http.Cookie{Name: cookie.Name, Value: "forged"}); rec.Code != http.StatusForbidden || *installs != 0 {
+		t.Fatalf("forged state got %d", rec.Code)
+	}
+	if rec := callback(app, "code=code-1&state="+url.QueryEscape(state), cookie); rec.Code != http.StatusOK || *installs != 1 {
+		t.Fatalf("got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := callback(app, "error=access_denied&state="+url.QueryEscape(state), cookie); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "canceled") || *installs != 1 {
+		t.Fatalf("access_denied got %d %s", rec.Code, rec.Body.String())
+	}
+
+	app, installs = newApp(&stateStore{states: map[string]time.Time{}})
+	state, cookie = start(app)
+	if cookie != nil {
+		t.Fatalf("store-backed state set cookie %+v", cookie)
+	}
+	callback(app, "code=code-1&state="+url.QueryEscape(state), nil)
+	if rec := callback(app, "code=code-1&state="+url.QueryEscape(state), nil); rec.Code != http.StatusForbidden || *installs != 1 {
+		t.Fatalf("reused state got %d, %d installs", rec.Code, *installs)
+	}
+
+	var installErr error
+	app.OnError(func(res http.ResponseWriter, req *http.Request, err error) {
+		installErr = err
+		res.WriteHeader(http.StatusBadGateway)
+	})
+	state, _ = start(app)
+	var apiErr *loafer.SlackAPIError
+	if rec := callback(app, "code=expired&state="+url.QueryEscape(state), nil); rec.Code != http.StatusBadGateway || !errors.As(installErr, &apiErr) || apiErr.Code != "invalid_code" || *installs != 1 {
+		t.Fatalf("got %d with %v", rec.Code, installErr)
+	}
+}
+
+type installationCache struct {
+	tokens map[loafer.InstallationKey]string
+}
+
+func (c *installationCache) Get(workspace string) string {
+	return ""
+}
+
+func (c *installationCache) Set(workspace string, token string) {}
+
+func (c *installationCache) Remove(workspace string) {}
+
+func (c *installationCache) GetInstallation(key loafer.InstallationKey) string {
+	return c.tokens[key]
+}
+
+func (c *installationCache) RemoveInstallation(key loafer.InstallationKey) {
+	delete(c.tokens, key)
+}
+
+func TestEnterpriseInstall(t *testing.T) {
+	app := loafer.InitializeSlackApp(&loafer.SlackAppOptions{
+		Prefix:        "dev",
+		TokensCache:   &TokenCache{tokens: map[string]string{"T1": "xoxb-team", "E1": "xoxb-org"}},
+		SigningSecret: "test-secret"})
+	var got []string
+	record := func(ctx *loafer.SlackContext) error {
+		got = append(got, ctx.Token+"@"+ctx.Workspace+"/"+ctx.Installation.String())
+		return nil
+	}
+	app.HandleCommand("/dev", record)
+	app.HandleAction("clicked", record)
+	app.HandleEvent("reaction_added", record)
+	serve := func(path string, body string) int {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, signedRequest(path, body, time.Now()))
+		return rec.Code
+	}
+	serve("/dev/commands", "command=/dev&team_id=T1&enterprise_id=E1")
+	serve("/dev/commands", "command=/dev&team_id=T2&enterprise_id=E1")
+	serve("/dev/actions", interactionBody(`{"type":"block_actions","team":null,"enterprise":{"id":"E1"},"is_enterprise_install":true,"actions":[{"action_id":"clicked"}]}`))
+	serve("/dev/events", `{"type":"event_callback","team_id":"T2","enterprise_id":"E1","authorizations":[{"enterprise_id":"E1","team_id":null,"is_enterprise_install":true}],"event":{"type":"reaction_added"}}`)
+	want := "xoxb-team@T1/T1,xoxb-org@T2/E1,xoxb-org@E1/E1,xoxb-org@T2/E1"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("got %v", got)
+	}
+	if code := serve("/dev/commands", "command=/dev&team_id=T3&enterprise_id=E2"); code != http.StatusUnauthorized {
+		t.Fatalf("got %d", code)
+	}
+
+	cache := &installationCache{tokens: map[loafer.InstallationKey]string{
+		{EnterpriseID: "E1", IsEnterpriseInstall: true}: "xoxb-org"}}
+	app = loafer.InitializeSlackApp(&loafer.SlackAppOptions{
+		Prefix:        "dev",
+		TokensCache:   cache,
+		SigningSecret: "test-secret"})
+	app.HandleCommand("/dev", record)
+	got = nil
+	serve("/dev/commands", "command=/dev&team_id=T2&enterprise_id=E1")
+	serve("/dev/events", `{"type":"event_callback","enterprise_id":"E1","authorizations":[{"enterprise_id":"E1","is_enterprise_install":true}],"event":{"type":"app_uninstalled"}}`)
+	if strings.Join(got, ",") != "xoxb-org@T2/E1" || len(cache.tokens) != 0 {
+		t.Fatalf("got %v, tokens %v", got, cache.tokens)
+	}
+}
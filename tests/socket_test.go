@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	loafer "github.com/arkjxu/loafer"
+)
+
+// fakeSocket - Server side of a websocket connection in the fake slack server
+type fakeSocket struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+func acceptSocket(t *testing.T, res http.ResponseWriter, req *http.Request) *fakeSocket {
+	accept := sha1.Sum([]byte(req.Header.Get("Sec-Websocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	conn, rw, err := res.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(accept[:]) + "\r\n\r\n")
+	rw.Flush()
+	return &fakeSocket{conn: conn, rw: rw}
+}
+
+func (s *fakeSocket) send(message string) {
+	frame := []byte{0x81}
+	if len(message) < 126 {
+		frame = append(frame, byte(len(message)))
+	} else {
+		frame = append(frame, 126, byte(len(message)>>8), byte(len(message)))
+	}
+	s.rw.Write(append(frame, message...))
+	s.rw.Flush()
+}
+
+func (s *fakeSocket) receive() (string, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(s.rw, head[:]); err != nil {
+		return "", err
+	}
+	length := int(head[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(s.rw, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	var mask [4]byte
+	io.ReadFull(s.rw, mask[:])
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(s.rw, payload); err != nil {
+		return "", err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return string(payload), nil
+}
+
+func TestSocketMode(t *testing.T) {
+	var connections int32
+	acks := make(chan string, 2)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/apps.connections.open":
+			if req.Header.Get("Authorization") != "Bearer xapp-test" {
+				t.Errorf("got authorization %q", req.Header.Get("Authorization"))
+			}
+			json.NewEncoder(res).Encode(map[string]interface{}{
+				"ok":  true,
+				"url": "ws" + strings.TrimPrefix(server.URL, "http") + "/link"})
+		case "/link":
+			socket := acceptSocket(t, res, req)
+			defer socket.conn.Close()
+			n := atomic.AddInt32(&connections, 1)
+			socket.send(`{"type":"hello"}`)
+			socket.send(`{"envelope_id":"env-` + strconv.Itoa(int(n)) + `","type":"slash_commands","accepts_response_payload":true,"payload":{"team_id":"T1","command":"/ping","text":"hi"}}`)
+			ack, err := socket.receive()
+			if err != nil {
+				return
+			}
+			acks <- ack
+			if n == 1 {
+				socket.send(`{"type":"disconnect","reason":"refresh_requested"}`)
+			}
+			socket.receive()
+		}
+	}))
+	defer server.Close()
+
+	opts := loafer.SlackAppOptions{
+		Prefix:      "dev",
+		TokensCache: &TokenCache{tokens: map[string]string{"T1": "xoxb-test"}},
+		AppToken:    "xapp-test",
+		APIBaseURL:  server.URL + "/api/"}
+	app := loafer.InitializeSlackApp(&opts)
+	app.OnCommand("/ping", func(ctx *loafer.SlackContext) {
+		loafer.Response(ctx, http.StatusOK, []byte(`{"text":"pong"}`), nil)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- app.ServeSocketMode(ctx)
+	}()
+	for _, want := range []string{
+		`{"envelope_id":"env-1","payload":{"text":"pong"}}`,
+		`{"envelope_id":"env-2","payload":{"text":"pong"}}`} {
+		select {
+		case ack := <-acks:
+			if ack != want {
+				t.Fatalf("got ack %s, want %s", ack, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for ack")
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSocketModeOversizedFrame(t *testing.T) {
+	var connections int32
+	acks := make(chan string, 1)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/apps.connections.open":
+			json.NewEncoder(res).Encode(map[string]interface{}{
+				"ok":  true,
+				"url": "ws" + strings.TrimPrefix(server.URL, "http") + "/link"})
+		case "/link":
+			socket := acceptSocket(t, res, req)
+			defer socket.conn.Close()
+			socket.send(`{"type":"hello"}`)
+			if atomic.AddInt32(&connections, 1) == 1 {
+				frame := []byte{0x81, 127, 0, 0, 0, 0, 0, 0, 0, 0}
+				binary.BigEndian.PutUint64(frame[2:], 1<<62)
+				socket.rw.Write(frame)
+				socket.rw.Flush()
+				socket.receive()
+				return
+			}
+			socket.send(`{"envelope_id":"env-2","type":"slash_commands","accepts_response_payload":true,"payload":{"team_id":"T1","command":"/ping"}}`)
+			if ack, err := socket.receive(); err == nil {
+				acks <- ack
+			}
+			socket.receive()
+		}
+	}))
+	defer server.Close()
+
+	app := loafer.InitializeSlackApp(&loafer.SlackAppOptions{
+		Prefix:      "dev",
+		TokensCache: &TokenCache{tokens: map[string]string{"T1": "xoxb-test"}},
+		AppToken:    "xapp-test",
+		APIBaseURL:  server.URL + "/api/"})
+	app.OnCommand("/ping", func(ctx *loafer.SlackContext) {
+		loafer.Response(ctx, http.StatusOK, []byte(`{"text":"pong"}`), nil)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- app.ServeSocketMode(ctx)
+	}()
+	select {
+	case ack := <-acks:
+		if ack != `{"envelope_id":"env-2","payload":{"text":"pong"}}` {
+			t.Fatalf("got ack %s", ack)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reconnection after an oversized frame")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,6 +1,12 @@
 package loafer
 
-import "net/http"
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
 
 // ISlackBlockKitUI - Slack Generic UI Kit
 type ISlackBlockKitUI interface{}
@@ -12,18 +18,186 @@ type TokensCache interface {
 	Remove(workspace string)
 }
 
+// EventStore - Remembers the event ids already delivered so retries are not handled twice
+type EventStore interface {
+	Seen(eventID string) bool // Records the event id, true when it was already recorded
+	Forget(eventID string)    // Removes the event id after its handler failed, so slack's retry runs it again
+}
+
+// StateStore - Keeps the OAuth states issued by /{prefix}/install/start until their callback
+type StateStore interface {
+	Add(state string, expiry time.Time)
+	Consume(state string) bool // Removes the state, true when it was added and hasn't expired
+}
+
+// InstallationKey - Identifies an installation, org-wide installs on Enterprise Grid have IsEnterpriseInstall set and no TeamID
+type InstallationKey struct {
+	EnterpriseID        string
+	TeamID              string
+	IsEnterpriseInstall bool
+}
+
+// InstallationCache - TokensCache that keys tokens on the whole installation, used instead of Get and Remove when implemented
+type InstallationCache interface {
+	GetInstallation(key InstallationKey) string
+	RemoveInstallation(key InstallationKey)
+}
+
 // SlackApp - A simple slack app starter kit
 type SlackApp struct {
-	opts              SlackAppOptions
-	server            *http.Server                                                                           // Slack App options
+	opts              SlackAppOptions                                                                        // Slack App options
+	server            *http.Server                                                                           // Server started by ServeApp
+	mux               *http.ServeMux                                                                         // Custom routes of the app
+	signatures        *signatureCache                                                                        // Recently seen request signatures
+	eventIDs          EventStore                                                                             // Event ids already delivered
 	distCB            func(installRes *SlackOauth2Response, res http.ResponseWriter, req *http.Request) bool // Handler for app distribution
 	errorCB           func(res http.ResponseWriter, req *http.Request, err error)                            // Handler for error
-	cmds              map[string]func(ctx *SlackContext)                                                     // List of command handlers
-	shortcutListeners map[string]func(ctx *SlackContext)                                                     // List of shortcut handlers
-	actionListeners   map[string]func(ctx *SlackContext)                                                     // List of action handlers
-	submitListeners   map[string]func(ctx *SlackContext)                                                     // List of view submission handlers
-	closeListeners    map[string]func(ctx *SlackContext)                                                     // List of view close handlers
-	eventListeners    map[string]func(ctx *SlackContext)                                                     // List of slack event listeners
+	verificationCB    func(req *http.Request, challenge string)                                              // Handler for url verification
+	uninstallCB       Handler                                                                                // Handler for app uninstall
+	userRevokeCB      func(ctx *SlackContext, userIDs []string) error                                        // Handler for revoked user tokens
+	uninstalls        *inFlight                                                                              // Installations being uninstalled
+	cmds              map[string]Handler                                                                     // List of command handlers
+	shortcutListeners map[string]Handler                                                                     // List of shortcut handlers
+	actionListeners   map[string]Handler                                                                     // List of action handlers
+	submitListeners   map[string]Handler                                                                     // List of view submission handlers
+	closeListeners    map[string]Handler                                                                     // List of view close handlers
+	eventListeners    map[string]Handler                                                                     // List of slack event listeners
+	optionsListeners  map[string]Handler                                                                     // List of external select options handlers
+	homeListeners     map[string]Handler                                                                     // List of app_home_opened handlers by tab
+	middleware        []Middleware                                                                           // Middleware around every handler
+	pool              *workerPool                                                                            // Handlers running after an early ack
+	responseURLs      *responseURLTracker                                                                    // Uses of the received response urls
+}
+
+// Handler - Slack request handler, returned errors go through OnError
+type Handler func(ctx *SlackContext) error
+
+// Middleware - Wraps a handler, e.g. for logging, auth checks or timing
+type Middleware func(next Handler) Handler
+
+// SlackRequestError - Error with the HTTP status sent back to slack
+type SlackRequestError struct {
+	Status int   // HTTP status of the response
+	Err    error // Underlying error
+}
+
+// PanicError - Panic recovered from a handler
+type PanicError struct {
+	Value interface{} // Value passed to panic
+	Stack []byte      // Stack trace of the panic
+}
+
+// responseTracker - Remembers whether a handler has started the response
+type responseTracker struct {
+	http.ResponseWriter
+	written bool
+}
+
+// workerPool - Bounded pool of handlers running in the background
+type workerPool struct {
+	slots     chan struct{}
+	queueSize int // Max jobs waiting for a slot
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	running   int // Jobs running or waiting for a slot
+	closed    bool
+}
+
+// responseURLTracker - Tracks the uses of response urls against slack's limits
+type responseURLTracker struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	urls expiringSet    // Received response urls until they expire
+	uses map[string]int // Uses of the received response urls
+}
+
+// detachedContext - Context keeping the values of a request context without its deadline and cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+// asyncResponse - Response of a handler running after the request has been acknowledged
+type asyncResponse struct {
+	header http.Header
+}
+
+// signatureCache - Short-lived cache of seen request signatures
+type signatureCache struct {
+	mu   sync.Mutex
+	seen expiringSet
+}
+
+// inFlight - Keys being handled by a request
+type inFlight struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+// expiringSet - Keys kept until their expiry, purged in insertion order so adding stays cheap
+type expiringSet struct {
+	expiries map[string]time.Time
+	order    []expiringKey
+	expired  func(key string) // Called with the keys removed by purge, if set
+}
+
+// expiringKey - Key of an expiringSet and the expiry it was added with
+type expiringKey struct {
+	key    string
+	expiry time.Time
+}
+
+// memoryEventStore - In-memory EventStore keeping event ids for a ttl
+type memoryEventStore struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	seen expiringSet
+}
+
+// Client - Slack Web API client, reuses its http.Client connections between calls
+type Client struct {
+	token       string       // Bot or user token used for the calls
+	baseURL     string       // Slack Web API base url
+	httpClient  *http.Client // Http client used for the calls
+	userAgent   string       // User agent sent with the calls
+	maxRetries  int          // Retries of rate limited calls
+	rateLimiter *RateLimiter // Queue of calls per workspace and method tier
+}
+
+// ClientOptions - Slack Web API client options
+type ClientOptions struct {
+	Token       string       // Bot or user token used for the calls
+	BaseURL     string       // Slack Web API base url, defaults to https://slack.com/api/
+	HTTPClient  *http.Client // Http client used for the calls, defaults to a shared client
+	UserAgent   string       // User agent sent with the calls
+	MaxRetries  int          // Retries of rate limited calls, defaults to 3, negative to disable
+	RateLimiter *RateLimiter // Optional queue of calls per workspace and method tier, can be shared between clients
+}
+
+// RateTier - Slack Web API rate limit tier
+type RateTier int
+
+// RateLimiter - Spaces out Web API calls per workspace and method tier
+type RateLimiter struct {
+	mu     sync.Mutex
+	next   map[string]time.Time // Earliest time the next call of a key can be sent
+	tiers  map[string]RateTier  // Tier of each Web API method
+	pruned time.Time            // Last time keys whose slot passed were removed
+}
+
+// SlackResponseMetadata - Slack Web API response metadata
+type SlackResponseMetadata struct {
+	Messages   []string `json:"messages,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// SlackAPIError - Error returned by a Slack Web API call that was not ok, check for it with errors.As
+type SlackAPIError struct {
+	Method           string                `json:"-"`       // Web API method that was called
+	StatusCode       int                   `json:"-"`       // HTTP status of the response
+	Code             string                `json:"error"`   // Slack error code, e.g. channel_not_found
+	Warning          string                `json:"warning"` // Slack warning, if any
+	ResponseMetadata SlackResponseMetadata `json:"response_metadata"`
 }
 
 // SlackBlockText - Slack Text
@@ -97,6 +271,18 @@ type SlackInputOption struct {
 	Value string          `json:"value,omitempty"`
 }
 
+// SlackOptionGroup - Slack Select option group
+type SlackOptionGroup struct {
+	Label   *SlackBlockText    `json:"label,omitempty"`
+	Options []SlackInputOption `json:"options"`
+}
+
+// SlackOptionsResponse - Options sent back to an external select
+type SlackOptionsResponse struct {
+	Options      []SlackInputOption `json:"options,omitempty"`
+	OptionGroups []SlackOptionGroup `json:"option_groups,omitempty"`
+}
+
 // SlackBlockActions - Slack Actions
 type SlackBlockActions struct {
 	Type     string           `json:"type,omitempty"`
@@ -118,10 +304,51 @@ type SlackInteractionUser struct {
 
 // SlackInteractionContainer - Slack Interaction Container
 type SlackInteractionContainer struct {
-	Type        string `json:"type,omitempty"`
+	Type        string `json:"type,omitempty"` // message or view
 	MessageTS   string `json:"message_ts,omitempty"`
+	ThreadTS    string `json:"thread_ts,omitempty"`
 	ChannelID   string `json:"channel_id,omitempty"`
-	IsEphemeral string `json:"is_ephemeral,omitempty"`
+	IsEphemeral bool   `json:"is_ephemeral,omitempty"`
+	ViewID      string `json:"view_id,omitempty"`
+}
+
+// SlackInteractionChannel - Slack Interaction Channel
+type SlackInteractionChannel struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// SlackInteractionMessage - Message the interaction came from
+type SlackInteractionMessage struct {
+	Type     string           `json:"type,omitempty"`
+	TS       string           `json:"ts,omitempty"`
+	ThreadTS string           `json:"thread_ts,omitempty"`
+	Text     string           `json:"text,omitempty"`
+	User     string           `json:"user,omitempty"`
+	BotID    string           `json:"bot_id,omitempty"`
+	Blocks   ISlackBlockKitUI `json:"blocks,omitempty"`
+}
+
+// ViewState - Values of the inputs of a view or message, keyed by block id then action id
+type ViewState struct {
+	Values map[string]map[string]SlackStateValue `json:"values,omitempty"`
+}
+
+// SlackStateValue - Value of an input element, only the fields of its type are set
+type SlackStateValue struct {
+	Type                  string             `json:"type,omitempty"`
+	Value                 string             `json:"value,omitempty"`
+	SelectedOption        *SlackInputOption  `json:"selected_option,omitempty"`
+	SelectedOptions       []SlackInputOption `json:"selected_options,omitempty"`
+	SelectedUser          string             `json:"selected_user,omitempty"`
+	SelectedUsers         []string           `json:"selected_users,omitempty"`
+	SelectedChannel       string             `json:"selected_channel,omitempty"`
+	SelectedChannels      []string           `json:"selected_channels,omitempty"`
+	SelectedConversation  string             `json:"selected_conversation,omitempty"`
+	SelectedConversations []string           `json:"selected_conversations,omitempty"`
+	SelectedDate          string             `json:"selected_date,omitempty"`
+	SelectedTime          string             `json:"selected_time,omitempty"`
+	SelectedDateTime      int64              `json:"selected_date_time,omitempty"`
 }
 
 // SlackInteractionTeam - Slack Interaction Team
@@ -132,56 +359,77 @@ type SlackInteractionTeam struct {
 
 // SlackInteractionAction - Slack Interaction Action
 type SlackInteractionAction struct {
+	SlackStateValue
 	ActionID string          `json:"action_id,omitempty"`
 	BlockID  string          `json:"block_id,omitempty"`
 	Text     *SlackBlockText `json:"text,omitempty"`
-	Value    string          `json:"value,omitempty"`
-	Type     string          `json:"type,omitempty"`
 	ActionTS string          `json:"action_ts,omitempty"`
 }
 
+// SlackResponseMessage - Message posted to a response_url
+type SlackResponseMessage struct {
+	ResponseType    string           `json:"response_type,omitempty"` // in_channel or ephemeral
+	Text            string           `json:"text,omitempty"`
+	Blocks          ISlackBlockKitUI `json:"blocks,omitempty"`
+	ThreadTS        string           `json:"thread_ts,omitempty"`
+	ReplaceOriginal bool             `json:"replace_original,omitempty"`
+	DeleteOriginal  bool             `json:"delete_original,omitempty"`
+}
+
+// SlackResponseURL - Response url of a view submission
+type SlackResponseURL struct {
+	BlockID     string `json:"block_id,omitempty"`
+	ActionID    string `json:"action_id,omitempty"`
+	ChannelID   string `json:"channel_id,omitempty"`
+	ResponseURL string `json:"response_url,omitempty"`
+}
+
 // SlackInteractionEvent - Slack Interaction Event
 type SlackInteractionEvent struct {
-	Type        string                      `json:"type,omitempty"`
-	User        *SlackInteractionUser       `json:"user,omitempty"`
-	APIAppID    string                      `json:"api_app_id,omitempty"`
-	Token       string                      `json:"token,omitempty"`
-	Container   *SlackInteractionContainer  `json:"blocks,omitempty"`
-	TriggerID   string                      `json:"trigger_id,omitempty"`
-	Team        *SlackInteractionTeam       `json:"team,omitempty"`
-	Channel     *SlackOauth2Team            `json:"channel,omitempty"`
-	ResponseURL string                      `json:"response_url,omitempty"`
-	Actions     []SlackInteractionAction    `json:"actions,omitempty"`
-	Value       string                      `json:"value,omitempty"`
-	State       map[string]ISlackBlockKitUI `json:"state,omitempty"`
-	View        *SlackInteractionView       `json:"view,omitempty"`
-	CallbackID  string                      `json:"callback_id,omitempty"`
-	ActionID    string                      `json:"action_id,omitempty"`
-	BlockID     string                      `json:"block_id,omitempty"`
-	ActionTS    string                      `json:"action_ts,omitempty"`
+	Type                string                     `json:"type,omitempty"`
+	User                *SlackInteractionUser      `json:"user,omitempty"`
+	APIAppID            string                     `json:"api_app_id,omitempty"`
+	Token               string                     `json:"token,omitempty"`
+	Container           *SlackInteractionContainer `json:"container,omitempty"`
+	TriggerID           string                     `json:"trigger_id,omitempty"`
+	Team                *SlackInteractionTeam      `json:"team,omitempty"`
+	Enterprise          *SlackOauth2Team           `json:"enterprise,omitempty"`
+	IsEnterpriseInstall bool                       `json:"is_enterprise_install,omitempty"`
+	Channel             *SlackInteractionChannel   `json:"channel,omitempty"`
+	Message             *SlackInteractionMessage   `json:"message,omitempty"`
+	ResponseURL         string                     `json:"response_url,omitempty"`
+	ResponseURLs        []SlackResponseURL         `json:"response_urls,omitempty"`
+	Actions             []SlackInteractionAction   `json:"actions,omitempty"`
+	Value               string                     `json:"value,omitempty"`
+	State               *ViewState                 `json:"state,omitempty"`
+	View                *SlackInteractionView      `json:"view,omitempty"`
+	CallbackID          string                     `json:"callback_id,omitempty"`
+	ActionID            string                     `json:"action_id,omitempty"`
+	BlockID             string                     `json:"block_id,omitempty"`
+	ActionTS            string                     `json:"action_ts,omitempty"`
 }
 
 // SlackInteractionView - Slack Interaction View
 type SlackInteractionView struct {
-	ID                 string                      `json:"id,omitempty"`
-	TeamID             string                      `json:"team_id,omitempty"`
-	Type               string                      `json:"type,omitempty"`
-	Blocks             ISlackBlockKitUI            `json:"blocks,omitempty"`
-	PrivateMetadata    string                      `json:"private_metadata,omitempty"`
-	CallbackID         string                      `json:"callback_id,omitempty"`
-	State              map[string]ISlackBlockKitUI `json:"state,omitempty"`
-	Hash               string                      `json:"hash,omitempty"`
-	Title              *SlackBlockText             `json:"title,omitempty"`
-	Close              *SlackBlockText             `json:"close,omitempty"`
-	Submit             *SlackBlockText             `json:"submit,omitempty"`
-	ClearOnClose       bool                        `json:"clear_on_close,omitempty"`
-	NotifyOnClose      bool                        `json:"notify_on_close,omitempty"`
-	PreviousViewID     string                      `json:"previous_view_id,omitempty"`
-	RootViewID         string                      `json:"root_view_id,omitempty"`
-	AppID              string                      `json:"app_id,omitempty"`
-	ExternalID         string                      `json:"external_id,omitempty"`
-	AppInstalledTeamID string                      `json:"app_installed_team_id,omitempty"`
-	BotID              string                      `json:"bot_id,omitempty"`
+	ID                 string           `json:"id,omitempty"`
+	TeamID             string           `json:"team_id,omitempty"`
+	Type               string           `json:"type,omitempty"`
+	Blocks             ISlackBlockKitUI `json:"blocks,omitempty"`
+	PrivateMetadata    string           `json:"private_metadata,omitempty"`
+	CallbackID         string           `json:"callback_id,omitempty"`
+	State              *ViewState       `json:"state,omitempty"`
+	Hash               string           `json:"hash,omitempty"`
+	Title              *SlackBlockText  `json:"title,omitempty"`
+	Close              *SlackBlockText  `json:"close,omitempty"`
+	Submit             *SlackBlockText  `json:"submit,omitempty"`
+	ClearOnClose       bool             `json:"clear_on_close,omitempty"`
+	NotifyOnClose      bool             `json:"notify_on_close,omitempty"`
+	PreviousViewID     string           `json:"previous_view_id,omitempty"`
+	RootViewID         string           `json:"root_view_id,omitempty"`
+	AppID              string           `json:"app_id,omitempty"`
+	ExternalID         string           `json:"external_id,omitempty"`
+	AppInstalledTeamID string           `json:"app_installed_team_id,omitempty"`
+	BotID              string           `json:"bot_id,omitempty"`
 }
 
 // SlackModal - Slack Modal
@@ -193,7 +441,25 @@ type SlackModal struct {
 	Blocks          ISlackBlockKitUI `json:"blocks,omitempty"`
 	CallbackID      string           `json:"callback_id,omitempty"`
 	NotifyOnClose   bool             `json:"notify_on_close,omitempty"`
+	ClearOnClose    bool             `json:"clear_on_close,omitempty"`
 	PrivateMetadata string           `json:"private_metadata,omitempty"`
+	ExternalID      string           `json:"external_id,omitempty"` // Unique id to update the view with instead of its view id
+}
+
+// SlackHomeView - Slack App Home tab view
+type SlackHomeView struct {
+	Type            string           `json:"type,omitempty"`
+	Blocks          ISlackBlockKitUI `json:"blocks,omitempty"`
+	CallbackID      string           `json:"callback_id,omitempty"`
+	PrivateMetadata string           `json:"private_metadata,omitempty"`
+	ExternalID      string           `json:"external_id,omitempty"`
+}
+
+// SlackViewResponse - response_action answering a view submission
+type SlackViewResponse struct {
+	ResponseAction string            `json:"response_action"` // errors, update, push or clear
+	View           *SlackModal       `json:"view,omitempty"`
+	Errors         map[string]string `json:"errors,omitempty"` // Error messages keyed by block id
 }
 
 // SlackInputElement - Slack Modal Plain text input
@@ -261,21 +527,102 @@ type SlackUser struct {
 
 // SlackAppOptions - Slack App options
 type SlackAppOptions struct {
-	Name          string      // Slack App name
-	Prefix        string      // Prefix of routes
-	TokensCache   TokensCache // List of available workspace tokens
-	ClientSecret  string      // App client secret
-	ClientID      string      // App client id
-	SigningSecret string      // Signning secret
+	Name           string           // Slack App name
+	Prefix         string           // Prefix of routes
+	TokensCache    TokensCache      // List of available workspace tokens
+	ClientSecret   string           // App client secret
+	ClientID       string           // App client id
+	BotScopes      []string         // Bot scopes requested by /{prefix}/install/start
+	UserScopes     []string         // User scopes requested by /{prefix}/install/start
+	RedirectURL    string           // OAuth redirect url, defaults to the one in the app config
+	StateStore     StateStore       // Store of OAuth states, defaults to a cookie in the installing browser
+	SigningSecret  string           // Signning secret
+	MaxClockSkew   time.Duration    // Max age of a request timestamp, defaults to 5 minutes
+	RejectReplays  bool             // Reject requests whose signature has been seen within the clock skew window
+	AppToken       string           // App-level token (xapp-), used for socket mode
+	APIBaseURL     string           // Slack Web API base url, defaults to https://slack.com/api/
+	HTTPClient     *http.Client     // Http client shared by the app's API calls
+	RateLimiter    *RateLimiter     // Rate limiter shared by the app's API calls
+	HandlerTimeout time.Duration    // Deadline of SlackContext.Context, defaults to slack's 3 seconds window
+	Workers        int              // Max handlers running in the background after an Ack, defaults to 10
+	QueueSize      int              // Max handlers waiting for a worker, Ack fails with a 503 beyond it, defaults to 100
+	EventStore     EventStore       // Delivered event ids, retries of those are acked without running the handler, defaults to memory for an hour
+	NoRetry        bool             // Ask slack not to retry events with the X-Slack-No-Retry header
+	Clock          func() time.Time // Current time of the response_url limits, defaults to time.Now
 }
 
 // SlackContext - Slack request context
 type SlackContext struct {
-	Body      []byte
-	Token     string
-	Workspace string
-	Req       *http.Request
-	Res       http.ResponseWriter
+	Body         []byte
+	Token        string
+	Workspace    string
+	Installation InstallationKey // Installation whose token is in Token
+	ResponseURL  string
+	Command      *SlashCommand
+	Interaction  *SlackInteractionEvent
+	Event        *SlackSubscriptionEventRequest
+	RetryNum     int    // X-Slack-Retry-Num of a retried event, 0 for the first delivery
+	RetryReason  string // X-Slack-Retry-Reason of a retried event, e.g. http_timeout
+	Context      context.Context
+	app          *SlackApp
+	Req          *http.Request
+	Res          http.ResponseWriter
+}
+
+// SlashCommand - Slack slash command payload
+type SlashCommand struct {
+	Token               string // Deprecated verification token
+	Command             string // Command, e.g. /coaching
+	Text                string // Text after the command
+	TeamID              string
+	TeamDomain          string
+	EnterpriseID        string
+	EnterpriseName      string
+	IsEnterpriseInstall bool
+	ChannelID           string
+	ChannelName         string
+	UserID              string
+	UserName            string
+	APIAppID            string
+	TriggerID           string // Trigger id to open a view with
+	ResponseURL         string // Url for delayed responses
+}
+
+// CommandArgType - Type of a subcommand argument
+type CommandArgType int
+
+// CommandRouter - Routes the text of a slash command to subcommands
+type CommandRouter struct {
+	command     string
+	subcommands map[string]*Subcommand
+	order       []string // Subcommand names in the order they were added, for help
+}
+
+// Subcommand - Subcommand of a CommandRouter
+type Subcommand struct {
+	Name        string
+	Description string
+	Args        []CommandArg
+	Handler     func(ctx *SlackContext, args *CommandArgs) error
+}
+
+// CommandArg - Positional argument of a subcommand
+type CommandArg struct {
+	Name     string
+	Type     CommandArgType
+	Values   []string // Accepted values of an ARGENUM argument
+	Optional bool     // Optional arguments must come last
+}
+
+// CommandArgs - Parsed arguments of a subcommand
+type CommandArgs struct {
+	values map[string]interface{}
+}
+
+// SlackMention - User or channel mention, e.g. <@U123|kevin>
+type SlackMention struct {
+	ID   string
+	Name string // Empty when slack sent the mention without a name
 }
 
 // SlackOauth2Team - Slack App Access Response Team
@@ -294,30 +641,190 @@ type SlackOauth2User struct {
 
 // SlackOauth2Response - Slack App Access Response
 type SlackOauth2Response struct {
-	Ok          bool            `json:"ok"`
-	AccessToken string          `json:"access_token"`
-	TokenType   string          `json:"token_type"`
-	Scope       string          `json:"scope"`
-	BotUserID   string          `json:"bot_user_id"`
-	AppID       string          `json:"app_id"`
-	Team        SlackOauth2Team `json:"team"`
-	Enterprise  SlackOauth2Team `json:"enterprise"`
-	AuthedUser  SlackOauth2User `json:"authed_user"`
+	Ok                  bool            `json:"ok"`
+	AccessToken         string          `json:"access_token"`
+	TokenType           string          `json:"token_type"`
+	Scope               string          `json:"scope"`
+	BotUserID           string          `json:"bot_user_id"`
+	AppID               string          `json:"app_id"`
+	Team                SlackOauth2Team `json:"team"`
+	Enterprise          SlackOauth2Team `json:"enterprise"`
+	AuthedUser          SlackOauth2User `json:"authed_user"`
+	IsEnterpriseInstall bool            `json:"is_enterprise_install"` // Org-wide install, Team is empty
 }
 
 // SlackSubscriptionEvent - Slack Subscription event
 type SlackSubscriptionEvent struct {
+	Type    string                `json:"type"`
+	Subtype string                `json:"subtype,omitempty"` // Subtype of a message event, e.g. message_changed
+	EventTS string                `json:"event_ts"`
+	User    string                `json:"user,omitempty"`    // Id of the user, when the event has one as a string
+	Channel string                `json:"channel,omitempty"` // Id of the channel, when the event has one as a string
+	Tab     string                `json:"tab,omitempty"`     // Tab of an app_home_opened event, home or messages
+	View    *SlackInteractionView `json:"view,omitempty"`    // Home tab view of an app_home_opened event, if one was published
+	Raw     json.RawMessage       `json:"-"`                 // Whole event, decode it with ctx.DecodeEvent
+}
+
+// SlackMessageEvent - message event and its subtypes
+type SlackMessageEvent struct {
+	Type            string             `json:"type"`
+	Subtype         string             `json:"subtype,omitempty"` // Empty for new messages, e.g. message_changed, message_deleted, bot_message
+	Channel         string             `json:"channel"`
+	ChannelType     string             `json:"channel_type,omitempty"` // channel, group, im or mpim
+	User            string             `json:"user,omitempty"`
+	BotID           string             `json:"bot_id,omitempty"`
+	Text            string             `json:"text,omitempty"`
+	Blocks          ISlackBlockKitUI   `json:"blocks,omitempty"`
+	TS              string             `json:"ts"`
+	ThreadTS        string             `json:"thread_ts,omitempty"`
+	EventTS         string             `json:"event_ts"`
+	Hidden          bool               `json:"hidden,omitempty"`
+	DeletedTS       string             `json:"deleted_ts,omitempty"`       // Message removed by message_deleted
+	Message         *SlackMessageEvent `json:"message,omitempty"`          // New message of message_changed
+	PreviousMessage *SlackMessageEvent `json:"previous_message,omitempty"` // Old message of message_changed and message_deleted
+}
+
+// SlackAppMentionEvent - app_mention event
+type SlackAppMentionEvent struct {
+	Type     string `json:"type"`
+	User     string `json:"user"`
+	Text     string `json:"text"`
+	TS       string `json:"ts"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+	Channel  string `json:"channel"`
+	EventTS  string `json:"event_ts"`
+}
+
+// SlackReactionItem - Item a reaction was added to or removed from
+type SlackReactionItem struct {
+	Type        string `json:"type"` // message, file or file_comment
+	Channel     string `json:"channel,omitempty"`
+	TS          string `json:"ts,omitempty"`
+	File        string `json:"file,omitempty"`
+	FileComment string `json:"file_comment,omitempty"`
+}
+
+// SlackReactionEvent - reaction_added and reaction_removed events
+type SlackReactionEvent struct {
+	Type     string            `json:"type"`
+	User     string            `json:"user"`
+	Reaction string            `json:"reaction"`
+	ItemUser string            `json:"item_user,omitempty"`
+	Item     SlackReactionItem `json:"item"`
+	EventTS  string            `json:"event_ts"`
+}
+
+// SlackMemberJoinedChannelEvent - member_joined_channel event
+type SlackMemberJoinedChannelEvent struct {
+	Type        string `json:"type"`
+	User        string `json:"user"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type,omitempty"`
+	Team        string `json:"team,omitempty"`
+	Inviter     string `json:"inviter,omitempty"`
+	EventTS     string `json:"event_ts"`
+}
+
+// SlackCreatedChannel - Channel of a channel_created event
+type SlackCreatedChannel struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Created int64  `json:"created"`
+	Creator string `json:"creator"`
+}
+
+// SlackChannelCreatedEvent - channel_created event
+type SlackChannelCreatedEvent struct {
+	Type    string              `json:"type"`
+	Channel SlackCreatedChannel `json:"channel"`
+	EventTS string              `json:"event_ts"`
+}
+
+// SlackTeamJoinEvent - team_join event
+type SlackTeamJoinEvent struct {
+	Type    string    `json:"type"`
+	User    SlackUser `json:"user"`
+	EventTS string    `json:"event_ts"`
+}
+
+// SlackAppUninstalledEvent - app_uninstalled event
+type SlackAppUninstalledEvent struct {
 	Type    string `json:"type"`
 	EventTS string `json:"event_ts"`
 }
 
+// SlackRevokedTokens - User ids whose tokens were revoked, by token type
+type SlackRevokedTokens struct {
+	OAuth []string `json:"oauth,omitempty"`
+	Bot   []string `json:"bot,omitempty"`
+}
+
+// SlackTokensRevokedEvent - tokens_revoked event
+type SlackTokensRevokedEvent struct {
+	Type    string             `json:"type"`
+	Tokens  SlackRevokedTokens `json:"tokens"`
+	EventTS string             `json:"event_ts"`
+}
+
+// SlackSharedLink - Link of a link_shared event
+type SlackSharedLink struct {
+	Domain string `json:"domain"`
+	URL    string `json:"url"`
+}
+
+// SlackLinkSharedEvent - link_shared event
+type SlackLinkSharedEvent struct {
+	Type      string            `json:"type"`
+	Channel   string            `json:"channel"`
+	User      string            `json:"user"`
+	MessageTS string            `json:"message_ts"`
+	ThreadTS  string            `json:"thread_ts,omitempty"`
+	UnfurlID  string            `json:"unfurl_id,omitempty"`
+	Source    string            `json:"source,omitempty"` // conversations_history or composer
+	Links     []SlackSharedLink `json:"links"`
+	EventTS   string            `json:"event_ts"`
+}
+
+// SlackAppHomeOpenedEvent - app_home_opened event
+type SlackAppHomeOpenedEvent struct {
+	Type    string                `json:"type"`
+	User    string                `json:"user"`
+	Channel string                `json:"channel"`
+	Tab     string                `json:"tab"`
+	View    *SlackInteractionView `json:"view,omitempty"`
+	EventTS string                `json:"event_ts"`
+}
+
 // SlackSubscriptionEventRequest - Slack subscription event
 type SlackSubscriptionEventRequest struct {
-	Token     string                 `json:"token"`
-	TeamID    string                 `json:"team_id"`
-	APIAppID  string                 `json:"api_app_id"`
-	Event     SlackSubscriptionEvent `json:"event"`
-	Type      string                 `json:"type"`
-	EventID   string                 `json:"event_id"`
-	EventTime uint32                 `json:"event_time"`
+	Token          string                    `json:"token"`
+	TeamID         string                    `json:"team_id"`
+	APIAppID       string                    `json:"api_app_id"`
+	Event          SlackSubscriptionEvent    `json:"event"`
+	Type           string                    `json:"type"`
+	EnterpriseID   string                    `json:"enterprise_id,omitempty"`
+	Authorizations []SlackEventAuthorization `json:"authorizations,omitempty"` // Installation the event was delivered for
+	EventID        string                    `json:"event_id"`
+	Challenge      string                    `json:"challenge,omitempty"` // Challenge of a url_verification request
+	EventTime      uint32                    `json:"event_time"`
+}
+
+// SlackEventAuthorization - Installation an event was delivered for
+type SlackEventAuthorization struct {
+	EnterpriseID        string `json:"enterprise_id,omitempty"`
+	TeamID              string `json:"team_id,omitempty"`
+	UserID              string `json:"user_id,omitempty"`
+	IsBot               bool   `json:"is_bot"`
+	IsEnterpriseInstall bool   `json:"is_enterprise_install"`
+}
+
+// SlackSocketEnvelope - Slack socket mode envelope
+type SlackSocketEnvelope struct {
+	EnvelopeID             string          `json:"envelope_id"`
+	Type                   string          `json:"type"`
+	Reason                 string          `json:"reason"`
+	Payload                json.RawMessage `json:"payload"`
+	AcceptsResponsePayload bool            `json:"accepts_response_payload"`
+	RetryAttempt           int             `json:"retry_attempt"`
+	RetryReason            string          `json:"retry_reason"`
 }